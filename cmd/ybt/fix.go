@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"resonai/ybt/internal/builders/autoimports"
+	"resonai/ybt/internal/workspace"
+)
+
+// externalAliases covers import aliases that resolve to real external Go
+// modules rather than workspace targets, plus "pb": this workspace's
+// convention for whichever proto_go_library a file's package actually
+// depends on, which isn't derivable from an import path the way a
+// go_library's own alias is. These aren't BUILD-derivable the way
+// ws.AutoImportsIndex()'s aliases are, so they're hand-maintained here.
+// The "pb" entry always resolves to proto/base_protos, whichever
+// proto_go_library the file being fixed depends on.
+var externalAliases = map[string]string{
+	"assert": "github.com/stretchr/testify/assert",
+	"figure": "github.com/common-nighthawk/go-figure",
+	"proto":  "github.com/golang/protobuf/proto",
+	"pb":     "proto/base_protos",
+}
+
+func runFix(ws *workspace.Workspace, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: ybt fix <file.go>...")
+	}
+	// No GoRoot index is supplied, so aliases of standard-library packages
+	// are not resolved.
+	idx := autoimports.Index{
+		Deps:      externalAliases,
+		Workspace: ws.AutoImportsIndex(),
+	}
+	exit := 0
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fixed, unresolved, err := autoimports.Fix(src, idx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, alias := range unresolved {
+			fmt.Fprintf(os.Stderr, "%s: could not resolve %q to any deps or workspace import\n", path, alias)
+			exit = 1
+		}
+		if err := os.WriteFile(path, fixed, 0o644); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if exit != 0 {
+		os.Exit(exit)
+	}
+	return nil
+}
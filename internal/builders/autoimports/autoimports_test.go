@@ -0,0 +1,100 @@
+package autoimports
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMissingAliasesFindsUnimportedSelector(t *testing.T) {
+	src := []byte(`package main
+
+import "flag"
+
+func main() {
+	who := flag.String("who", "world", "who to greet")
+	flag.Parse()
+	greet := helloLib.GetGreet(*who)
+	_ = greet
+}
+`)
+	got, err := MissingAliases(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"helloLib"}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("MissingAliases() = %v, want %v", got, want)
+	}
+}
+
+func TestMissingAliasesIgnoresLocalsAndParams(t *testing.T) {
+	src := []byte(`package hello_lib
+
+type greeter struct{}
+
+func (g greeter) Greet(who string) string {
+	buf := who
+	return buf.String()
+}
+`)
+	got, err := MissingAliases(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("MissingAliases() = %v, want none (buf is a local, not a package)", got)
+	}
+}
+
+func TestResolvePrefersDepsOverWorkspaceOverGoRoot(t *testing.T) {
+	idx := Index{
+		Deps:      map[string]string{"helloLib": "bar.com/hello_lib"},
+		Workspace: map[string]string{"helloLib": "some/other/hello_lib"},
+		GoRoot:    map[string]string{"helloLib": "unrelated/stdlib/path"},
+	}
+	path, scope, ok := idx.Resolve("helloLib")
+	if !ok || path != "bar.com/hello_lib" || scope != "deps" {
+		t.Fatalf("Resolve() = (%q, %q, %v), want (bar.com/hello_lib, deps, true)", path, scope, ok)
+	}
+}
+
+func TestFixInsertsResolvedImport(t *testing.T) {
+	src := []byte(`package main
+
+import "flag"
+
+func main() {
+	who := flag.String("who", "world", "who to greet")
+	flag.Parse()
+	greet := helloLib.GetGreet(*who)
+	_ = greet
+}
+`)
+	idx := Index{Deps: map[string]string{"helloLib": "bar.com/hello_lib"}}
+	fixed, unresolved, err := Fix(src, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 0 {
+		t.Fatalf("unresolved = %v, want none", unresolved)
+	}
+	if !strings.Contains(string(fixed), `helloLib "bar.com/hello_lib"`) {
+		t.Fatalf("Fix() output missing inserted import:\n%s", fixed)
+	}
+}
+
+func TestFixReportsUnresolvedAliases(t *testing.T) {
+	src := []byte(`package main
+
+func main() {
+	mystery.Do()
+}
+`)
+	_, unresolved, err := Fix(src, Index{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 1 || unresolved[0] != "mystery" {
+		t.Fatalf("unresolved = %v, want [mystery]", unresolved)
+	}
+}
@@ -0,0 +1,205 @@
+// Package gogoldentest implements the GoGoldenTest target type: it runs a
+// built GoProgram against a matrix of BUILD-declared argv/env cases and
+// diffs its output against checked-in .golden files, with a --regenerate
+// mode that rewrites them in place instead of failing.
+package gogoldentest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"resonai/ybt/internal/target"
+)
+
+// Case is one entry in a GoGoldenTest's cases matrix.
+type Case struct {
+	Name string
+	Argv []string
+	Env  map[string]string
+}
+
+// NormalizeRule rewrites a volatile substring (a timestamp, a tempdir, a
+// pid, ...) to a stable placeholder before diffing against the golden
+// file, so golden files don't flake on irrelevant output.
+type NormalizeRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// GoldenTest describes a GoGoldenTest target.
+type GoldenTest struct {
+	Name        string
+	Program     string // label of the GoProgram target under test
+	TestdataDir string
+	Cases       []Case
+	Normalize   []NormalizeRule
+}
+
+// GoldenPath returns the path of the golden file for the named case.
+func (g *GoldenTest) GoldenPath(caseName string) string {
+	return filepath.Join(g.TestdataDir, caseName+".golden")
+}
+
+// normalize applies every NormalizeRule to output, in order.
+func (g *GoldenTest) normalize(output string) string {
+	for _, r := range g.Normalize {
+		output = r.Pattern.ReplaceAllString(output, r.Replacement)
+	}
+	return output
+}
+
+// CacheKey hashes every attribute that affects this golden test's outcome.
+func (g *GoldenTest) CacheKey() *target.CacheKey {
+	k := target.NewCacheKey().Add("program", g.Program)
+	for _, c := range g.Cases {
+		k.Add("case."+c.Name+".argv", c.Argv...)
+		k.AddSorted("case."+c.Name+".env", target.MapToSorted(c.Env)...)
+	}
+	return k
+}
+
+// Runner executes the built program for one case and returns its combined
+// stdout+stderr. It's supplied by the caller (the real implementation
+// shells out to the built GoProgram binary); tests supply a fake.
+type Runner func(c Case) (output string, err error)
+
+// CaseResult is the outcome of checking one Case against its golden file.
+type CaseResult struct {
+	Name    string
+	Passed  bool
+	Diff    string // unified diff of golden vs. actual, empty when Passed
+	Skipped bool   // true when regenerating rather than checking
+	Message string
+}
+
+// Check runs every case through run, normalizes its output, and diffs it
+// against the checked-in golden file. When regenerate is true, it writes
+// the normalized output to the golden file instead of diffing.
+func (g *GoldenTest) Check(run Runner, regenerate bool) ([]CaseResult, error) {
+	results := make([]CaseResult, 0, len(g.Cases))
+	for _, c := range g.Cases {
+		out, err := run(c)
+		if err != nil {
+			return nil, fmt.Errorf("case %q: running program: %w", c.Name, err)
+		}
+		out = g.normalize(out)
+		goldenPath := g.GoldenPath(c.Name)
+
+		if regenerate {
+			if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+				return nil, fmt.Errorf("case %q: %w", c.Name, err)
+			}
+			if err := os.WriteFile(goldenPath, []byte(out), 0o644); err != nil {
+				return nil, fmt.Errorf("case %q: writing golden: %w", c.Name, err)
+			}
+			results = append(results, CaseResult{Name: c.Name, Passed: true, Skipped: true, Message: "regenerated"})
+			continue
+		}
+
+		want, err := os.ReadFile(goldenPath)
+		if err != nil {
+			return nil, fmt.Errorf("case %q: reading golden %s: %w", c.Name, goldenPath, err)
+		}
+		if string(want) == out {
+			results = append(results, CaseResult{Name: c.Name, Passed: true})
+			continue
+		}
+		results = append(results, CaseResult{
+			Name: c.Name,
+			Diff: UnifiedDiff(goldenPath, string(want), "actual", out),
+		})
+	}
+	return results, nil
+}
+
+// UnifiedDiff renders a minimal unified diff between two line-oriented
+// strings, using a longest-common-subsequence line match.
+func UnifiedDiff(aName, a, bName, b string) string {
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	ops := diffLines(aLines, bLines)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", aName)
+	fmt.Fprintf(&sb, "+++ %s\n", bName)
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			fmt.Fprintf(&sb, " %s\n", op.line)
+		case diffRemove:
+			fmt.Fprintf(&sb, "-%s\n", op.line)
+		case diffAdd:
+			fmt.Fprintf(&sb, "+%s\n", op.line)
+		}
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type diffKind int
+
+const (
+	diffEqual diffKind = iota
+	diffRemove
+	diffAdd
+)
+
+type diffOp struct {
+	kind diffKind
+	line string
+}
+
+// diffLines computes a line-level diff of a and b via a classic LCS table,
+// filled back to front with suffix match lengths, then walks it front to
+// back to emit equal/remove/add operations in document order.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{diffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{diffRemove, a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{diffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{diffRemove, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{diffAdd, b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,91 @@
+package gogoldentest
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheckPassesOnMatchingGolden(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "default.golden"), []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := &GoldenTest{
+		TestdataDir: dir,
+		Cases:       []Case{{Name: "default"}},
+	}
+	results, err := g.Check(func(Case) (string, error) { return "hello world\n", nil }, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Passed || results[0].Diff != "" {
+		t.Fatalf("expected a passing, diff-free result, got %+v", results)
+	}
+}
+
+func TestCheckReportsUnifiedDiffOnMismatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "default.golden"), []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := &GoldenTest{
+		TestdataDir: dir,
+		Cases:       []Case{{Name: "default"}},
+	}
+	results, err := g.Check(func(Case) (string, error) { return "hello boomer\n", nil }, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Passed {
+		t.Fatalf("expected a failing result, got %+v", results)
+	}
+	if got := results[0].Diff; !strings.Contains(got, "-hello world") || !strings.Contains(got, "+hello boomer") {
+		t.Fatalf("diff missing expected +/- lines:\n%s", got)
+	}
+}
+
+func TestCheckRegeneratesGolden(t *testing.T) {
+	dir := t.TempDir()
+	g := &GoldenTest{
+		TestdataDir: dir,
+		Cases:       []Case{{Name: "default"}},
+	}
+	results, err := g.Check(func(Case) (string, error) { return "fresh output\n", nil }, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Passed || !results[0].Skipped {
+		t.Fatalf("expected a skipped/regenerated result, got %+v", results)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "default.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "fresh output\n" {
+		t.Fatalf("golden file = %q, want %q", got, "fresh output\n")
+	}
+}
+
+func TestNormalizeStripsVolatileSubstrings(t *testing.T) {
+	g := &GoldenTest{
+		Normalize: []NormalizeRule{
+			{Pattern: regexp.MustCompile(`/tmp/[a-zA-Z0-9_-]+`), Replacement: "TMPDIR"},
+		},
+	}
+	got := g.normalize("wrote to /tmp/ybt-run-8f2c1\n")
+	want := "wrote to TMPDIR\n"
+	if got != want {
+		t.Fatalf("normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestCacheKeyChangesWithCaseArgv(t *testing.T) {
+	base := &GoldenTest{Program: ":hello_proto", Cases: []Case{{Name: "default", Argv: nil}}}
+	changed := &GoldenTest{Program: ":hello_proto", Cases: []Case{{Name: "default", Argv: []string{"-who=boomer"}}}}
+	if base.CacheKey().String() == changed.CacheKey().String() {
+		t.Fatal("expected cache key to change when a case's argv changes")
+	}
+}
@@ -0,0 +1,82 @@
+// Package gotest implements the GoTest target type's test_args, test_env,
+// and setup/teardown attributes: the pieces of a test run that get baked
+// into the compiled test binary's argv/env rather than expressed as Go
+// source.
+package gotest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"resonai/ybt/internal/target"
+)
+
+// Test describes a GoTest target.
+type Test struct {
+	Name     string
+	Srcs     []string
+	Deps     []string
+	TestArgs []string
+	TestEnv  map[string]string
+	// Setup lists helper packages (e.g. a shared fixture package like
+	// goodbye_lib's test_utils) compiled into the test binary alongside
+	// Srcs; they are blank-imported, so only their init() functions run.
+	Setup []string
+	// Teardown lists helper packages compiled in the same way as Setup
+	// ones (blank-imported, so their init() functions run before the
+	// tests); nothing is run on their behalf after m.Run() returns.
+	Teardown []string
+}
+
+// Argv returns the argv appended to the compiled test binary, equivalent to
+// `go test -args <TestArgs...>`.
+func (t *Test) Argv() []string {
+	return append([]string(nil), t.TestArgs...)
+}
+
+// Env returns TestEnv as "KEY=VALUE" pairs, sorted by key so the result is
+// deterministic across runs.
+func (t *Test) Env() []string {
+	return target.MapToSorted(t.TestEnv)
+}
+
+// CacheKey hashes every attribute that affects the test binary or its
+// behavior, so changing a flag, an env var, or a setup/teardown package
+// invalidates any cached pass/fail result for this target.
+func (t *Test) CacheKey() *target.CacheKey {
+	return target.NewCacheKey().
+		Add("srcs", t.Srcs...).
+		AddSorted("deps", t.Deps...).
+		Add("test_args", t.TestArgs...).
+		AddSorted("test_env", target.MapToSorted(t.TestEnv)...).
+		AddSorted("setup", t.Setup...).
+		AddSorted("teardown", t.Teardown...)
+}
+
+// Registry maps target name to its Test definition, so `ybt test --help`
+// can surface each target's flag defaults without re-evaluating BUILD
+// files.
+type Registry map[string]*Test
+
+// PrintHelp writes a "name:" line per registered target, sorted by target
+// name, each followed by an indented line for its test_args and one for
+// its test_env defaults when they are set.
+func PrintHelp(w io.Writer, reg Registry) {
+	names := make([]string, 0, len(reg))
+	for name := range reg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := reg[name]
+		fmt.Fprintf(w, "%s:\n", name)
+		if len(t.TestArgs) > 0 {
+			fmt.Fprintf(w, "  test_args: %v\n", t.TestArgs)
+		}
+		if len(t.TestEnv) > 0 {
+			fmt.Fprintf(w, "  test_env: %v\n", t.Env())
+		}
+	}
+}
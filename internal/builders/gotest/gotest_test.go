@@ -0,0 +1,59 @@
+package gotest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestArgvAndEnv(t *testing.T) {
+	tt := &Test{
+		TestArgs: []string{"-greet_to=boomer"},
+		TestEnv:  map[string]string{"FOO": "bar", "BAZ": "qux"},
+	}
+	if got, want := tt.Argv(), []string{"-greet_to=boomer"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Argv() = %v, want %v", got, want)
+	}
+	if got, want := tt.Env(), []string{"BAZ=qux", "FOO=bar"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Env() = %v, want %v", got, want)
+	}
+}
+
+func TestCacheKeyChangesWithTestArgs(t *testing.T) {
+	base := &Test{Srcs: []string{"utils_test.go"}, TestArgs: []string{"-greet_to=wrong"}}
+	changed := &Test{Srcs: []string{"utils_test.go"}, TestArgs: []string{"-greet_to=boomer"}}
+	if base.CacheKey().String() == changed.CacheKey().String() {
+		t.Fatal("expected cache key to change when test_args changes")
+	}
+}
+
+func TestCacheKeyChangesWithSetup(t *testing.T) {
+	base := &Test{Srcs: []string{"goodbye_test.go"}}
+	withSetup := &Test{Srcs: []string{"goodbye_test.go"}, Setup: []string{"//tests/golang/goodbye_lib:test_utils"}}
+	if base.CacheKey().String() == withSetup.CacheKey().String() {
+		t.Fatal("expected cache key to change when setup changes")
+	}
+}
+
+func TestPrintHelpListsTargetsSorted(t *testing.T) {
+	reg := Registry{
+		"hello_lib_test": {TestArgs: []string{"-greet_to=boomer"}},
+		"goodbye_test":   {Setup: []string{"//tests/golang/goodbye_lib:test_utils"}},
+	}
+	var buf bytes.Buffer
+	PrintHelp(&buf, reg)
+
+	got := buf.String()
+	wantOrder := []string{"goodbye_test:", "hello_lib_test:", "test_args: [-greet_to=boomer]"}
+	lastIdx := -1
+	for _, w := range wantOrder {
+		idx := bytes.Index([]byte(got), []byte(w))
+		if idx == -1 {
+			t.Fatalf("PrintHelp output missing %q, got:\n%s", w, got)
+		}
+		if idx <= lastIdx {
+			t.Fatalf("expected %q to come after previous entries, got:\n%s", w, got)
+		}
+		lastIdx = idx
+	}
+}
@@ -0,0 +1,126 @@
+// Package grpcservice implements the GoGrpcService and GrpcIntegrationTest
+// target types: a gRPC server fixture plus a harness that spawns it on an
+// ephemeral port, waits for readiness, runs a client test binary against
+// it, and tears the server down afterward.
+package grpcservice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"resonai/ybt/internal/builders/protogolibrary"
+	"resonai/ybt/internal/target"
+)
+
+// Service describes a GoGrpcService target: a GoProgram that additionally
+// knows how to serve TLS and register interceptors.
+type Service struct {
+	Name         string
+	Srcs         []string
+	Deps         []string
+	CertFile     string // empty means plaintext
+	KeyFile      string
+	Interceptors []string // interceptor names, passed to the server as repeated -interceptor flags
+}
+
+// ServerArgs returns the argv this service's binary is invoked with, beyond
+// whatever address flag the IntegrationTest harness injects.
+func (s *Service) ServerArgs() []string {
+	var args []string
+	if s.CertFile != "" || s.KeyFile != "" {
+		args = append(args, "-tls_cert_file="+s.CertFile, "-tls_key_file="+s.KeyFile)
+	}
+	for _, i := range s.Interceptors {
+		args = append(args, "-interceptor="+i)
+	}
+	return args
+}
+
+// ProtoDeps resolves which of this service's Deps are ProtoGoLibrary
+// targets, in declaration order, so a build step can run their codegen
+// before compiling the service against the result (see
+// internal/builders/protogolibrary).
+func (s *Service) ProtoDeps(protoLibs map[string]*protogolibrary.Library) []*protogolibrary.Library {
+	var out []*protogolibrary.Library
+	for _, dep := range s.Deps {
+		if lib, ok := protoLibs[dep]; ok {
+			out = append(out, lib)
+		}
+	}
+	return out
+}
+
+// CacheKey hashes every attribute that affects this service's behavior.
+func (s *Service) CacheKey() *target.CacheKey {
+	return target.NewCacheKey().
+		Add("srcs", s.Srcs...).
+		AddSorted("deps", s.Deps...).
+		Add("cert_file", s.CertFile).
+		Add("key_file", s.KeyFile).
+		Add("interceptors", s.Interceptors...)
+}
+
+// StreamingCase describes one streaming-call fixture exercised by a
+// GrpcIntegrationTest: the messages the client sends on the stream and the
+// messages it expects back, in order.
+type StreamingCase struct {
+	Name    string
+	Sends   []string
+	Expects []string
+}
+
+// IntegrationTest describes a GrpcIntegrationTest target.
+type IntegrationTest struct {
+	Name     string
+	Srcs     []string // the client test's own sources, e.g. client_test.go
+	Deps     []string
+	Service  string // label of the GoGrpcService target under test
+	AddrFlag string // flag injected into both server and client, e.g. "-addr"
+	// ReadyTimeout bounds how long Harness.Run waits for the server to
+	// print its readiness line before giving up.
+	ReadyTimeout time.Duration
+	Streaming    []StreamingCase
+}
+
+// CacheKey hashes every attribute that affects this integration test's
+// outcome.
+func (it *IntegrationTest) CacheKey() *target.CacheKey {
+	k := target.NewCacheKey().
+		Add("srcs", it.Srcs...).
+		AddSorted("deps", it.Deps...).
+		Add("service", it.Service).
+		Add("addr_flag", it.AddrFlag).
+		Add("ready_timeout", it.ReadyTimeout.String())
+	for _, sc := range it.Streaming {
+		k.Add("stream."+sc.Name+".sends", sc.Sends...)
+		k.Add("stream."+sc.Name+".expects", sc.Expects...)
+	}
+	return k
+}
+
+// ClientArgsForService returns the extra argv the client test binary needs
+// to reach svc: when svc serves TLS, the client is pointed at the same
+// cert so it can verify the server (this fixture uses a single self-signed
+// cert as its own CA, not a separate trust root).
+func (it *IntegrationTest) ClientArgsForService(svc *Service) []string {
+	if svc.CertFile == "" {
+		return nil
+	}
+	return []string{"-tls_cert_file=" + svc.CertFile}
+}
+
+// ClientArgs returns the extra argv a client test binary needs to exercise
+// this streaming case, mirroring how ClientArgsForService threads TLS
+// material: the BUILD-declared sends/expects become flags instead of
+// being hardcoded into the test source.
+func (sc StreamingCase) ClientArgs() []string {
+	return []string{
+		"-echo_stream_sends=" + strings.Join(sc.Sends, ","),
+		"-echo_stream_expects=" + strings.Join(sc.Expects, ","),
+	}
+}
+
+// ErrReadyTimeout is returned by Harness.Run when the server doesn't print
+// its readiness line within ReadyTimeout.
+var ErrReadyTimeout = fmt.Errorf("grpcservice: server did not become ready in time")
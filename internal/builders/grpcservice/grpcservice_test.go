@@ -0,0 +1,99 @@
+package grpcservice
+
+import (
+	"reflect"
+	"testing"
+
+	"resonai/ybt/internal/builders/protogolibrary"
+)
+
+func TestServerArgsIncludesTLSAndInterceptors(t *testing.T) {
+	s := &Service{
+		CertFile:     "server.crt",
+		KeyFile:      "server.key",
+		Interceptors: []string{"//tests/golang/greeter_service:logging_interceptor"},
+	}
+	got := s.ServerArgs()
+	want := []string{
+		"-tls_cert_file=server.crt",
+		"-tls_key_file=server.key",
+		"-interceptor=//tests/golang/greeter_service:logging_interceptor",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ServerArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestServerArgsPlaintextHasNoTLSFlags(t *testing.T) {
+	s := &Service{}
+	if got := s.ServerArgs(); len(got) != 0 {
+		t.Fatalf("ServerArgs() = %v, want none for a plaintext service", got)
+	}
+}
+
+func TestServiceCacheKeyChangesWithCertFile(t *testing.T) {
+	plain := &Service{Srcs: []string{"server.go"}}
+	tls := &Service{Srcs: []string{"server.go"}, CertFile: "server.crt", KeyFile: "server.key"}
+	if plain.CacheKey().String() == tls.CacheKey().String() {
+		t.Fatal("expected cache key to change when TLS material changes")
+	}
+}
+
+func TestClientArgsForServiceAddsCertWhenTLSEnabled(t *testing.T) {
+	it := &IntegrationTest{Service: ":greeter_service"}
+	svc := &Service{CertFile: "testdata/tls/server.crt", KeyFile: "testdata/tls/server.key"}
+	got := it.ClientArgsForService(svc)
+	want := []string{"-tls_cert_file=testdata/tls/server.crt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ClientArgsForService() = %v, want %v", got, want)
+	}
+}
+
+func TestClientArgsForServiceEmptyWhenPlaintext(t *testing.T) {
+	it := &IntegrationTest{Service: ":greeter_service"}
+	if got := it.ClientArgsForService(&Service{}); len(got) != 0 {
+		t.Fatalf("ClientArgsForService() = %v, want none for a plaintext service", got)
+	}
+}
+
+func TestProtoDepsFiltersToProtoGoLibraryTargets(t *testing.T) {
+	s := &Service{Deps: []string{
+		"//tests/proto/base_protos:base_protos",
+		"//tests/proto/greeter:greeter_proto",
+	}}
+	protoLibs := map[string]*protogolibrary.Library{
+		"//tests/proto/base_protos:base_protos": {Name: "base_protos"},
+		"//tests/proto/greeter:greeter_proto":    {Name: "greeter_proto"},
+	}
+	got := s.ProtoDeps(protoLibs)
+	if len(got) != 2 || got[0].Name != "base_protos" || got[1].Name != "greeter_proto" {
+		t.Fatalf("ProtoDeps() = %+v", got)
+	}
+}
+
+func TestProtoDepsSkipsNonProtoDeps(t *testing.T) {
+	s := &Service{Deps: []string{"//tests/golang/greeter_service:logging_interceptor"}}
+	if got := s.ProtoDeps(map[string]*protogolibrary.Library{}); len(got) != 0 {
+		t.Fatalf("ProtoDeps() = %v, want none", got)
+	}
+}
+
+func TestStreamingCaseClientArgs(t *testing.T) {
+	sc := StreamingCase{Name: "EchoStream", Sends: []string{"ping", "pong"}, Expects: []string{"ping", "pong"}}
+	got := sc.ClientArgs()
+	want := []string{"-echo_stream_sends=ping,pong", "-echo_stream_expects=ping,pong"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ClientArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestIntegrationTestCacheKeyChangesWithStreamingCase(t *testing.T) {
+	base := &IntegrationTest{Service: ":greeter_service"}
+	streaming := &IntegrationTest{
+		Service:   ":greeter_service",
+		Streaming: []StreamingCase{{Name: "echo", Sends: []string{"a", "b"}, Expects: []string{"a", "b"}}},
+	}
+	if base.CacheKey().String() == streaming.CacheKey().String() {
+		t.Fatal("expected cache key to change when a streaming case is added")
+	}
+}
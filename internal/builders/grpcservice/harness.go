@@ -0,0 +1,84 @@
+package grpcservice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+// Harness spawns a server process, waits for it to announce readiness on
+// stdout, runs a client process against the address it announced, and
+// tears the server down once the client finishes.
+type Harness struct {
+	// NewServerCmd builds the (not-yet-started) server command.
+	NewServerCmd func() *exec.Cmd
+	// ReadyPattern is matched against each line the server writes to
+	// stdout; its first capture group is the address the client should
+	// connect to. A line like "listening on 127.0.0.1:54321" pairs with
+	// the pattern `listening on (\S+)`.
+	ReadyPattern *regexp.Regexp
+	// ReadyTimeout bounds how long Run waits for a ReadyPattern match
+	// before returning ErrReadyTimeout.
+	ReadyTimeout time.Duration
+}
+
+// Run starts the server, waits for readiness, runs newClientCmd(addr) to
+// completion, kills and reaps the server, and returns the client's combined
+// stdout+stderr.
+func (h *Harness) Run(newClientCmd func(addr string) *exec.Cmd) (clientOutput string, err error) {
+	serverCmd := h.NewServerCmd()
+	stdout, err := serverCmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("grpcservice: server stdout pipe: %w", err)
+	}
+	if err := serverCmd.Start(); err != nil {
+		return "", fmt.Errorf("grpcservice: starting server: %w", err)
+	}
+	defer func() {
+		_ = serverCmd.Process.Kill()
+		_ = serverCmd.Wait()
+	}()
+
+	addr, err := waitForReady(stdout, h.ReadyPattern, h.ReadyTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	clientCmd := newClientCmd(addr)
+	out, runErr := clientCmd.CombinedOutput()
+	if runErr != nil {
+		return string(out), fmt.Errorf("grpcservice: client failed: %w", runErr)
+	}
+	return string(out), nil
+}
+
+// waitForReady scans r line by line until ReadyPattern matches or timeout
+// elapses.
+func waitForReady(r io.Reader, pattern *regexp.Regexp, timeout time.Duration) (string, error) {
+	type result struct {
+		addr string
+		err  error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if m := pattern.FindStringSubmatch(scanner.Text()); m != nil {
+				done <- result{addr: m[1]}
+				return
+			}
+		}
+		done <- result{err: fmt.Errorf("grpcservice: server exited before printing a readiness line")}
+	}()
+
+	select {
+	case res := <-done:
+		return res.addr, res.err
+	case <-time.After(timeout):
+		return "", ErrReadyTimeout
+	}
+}
@@ -0,0 +1,96 @@
+package grpcservice
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var readyPattern = regexp.MustCompile(`listening on (\S+)`)
+
+func TestHarnessRunWaitsForReadinessThenRunsClient(t *testing.T) {
+	h := &Harness{
+		NewServerCmd: func() *exec.Cmd {
+			return exec.Command("sh", "-c", "echo 'listening on 127.0.0.1:54321'; sleep 5")
+		},
+		ReadyPattern: readyPattern,
+		ReadyTimeout: 2 * time.Second,
+	}
+
+	var gotAddr string
+	out, err := h.Run(func(addr string) *exec.Cmd {
+		gotAddr = addr
+		return exec.Command("sh", "-c", "echo client saw $ADDR", "ignored")
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if gotAddr != "127.0.0.1:54321" {
+		t.Fatalf("client addr = %q, want 127.0.0.1:54321", gotAddr)
+	}
+	if !strings.Contains(out, "client saw") {
+		t.Fatalf("client output = %q", out)
+	}
+}
+
+func TestHarnessRunTimesOutWhenServerNeverReady(t *testing.T) {
+	h := &Harness{
+		NewServerCmd: func() *exec.Cmd {
+			return exec.Command("sh", "-c", "sleep 5")
+		},
+		ReadyPattern: readyPattern,
+		ReadyTimeout: 200 * time.Millisecond,
+	}
+
+	_, err := h.Run(func(addr string) *exec.Cmd {
+		t.Fatal("client should never run when the server is never ready")
+		return nil
+	})
+	if err != ErrReadyTimeout {
+		t.Fatalf("Run() error = %v, want ErrReadyTimeout", err)
+	}
+}
+
+func TestHarnessRunPropagatesClientFailure(t *testing.T) {
+	h := &Harness{
+		NewServerCmd: func() *exec.Cmd {
+			return exec.Command("sh", "-c", "echo 'listening on 127.0.0.1:1'; sleep 5")
+		},
+		ReadyPattern: readyPattern,
+		ReadyTimeout: 2 * time.Second,
+	}
+
+	_, err := h.Run(func(addr string) *exec.Cmd {
+		return exec.Command("sh", "-c", "exit 1")
+	})
+	if err == nil {
+		t.Fatal("expected an error when the client exits non-zero")
+	}
+}
+
+func TestHarnessTearsDownServerAfterClient(t *testing.T) {
+	var serverCmd *exec.Cmd
+	h := &Harness{
+		NewServerCmd: func() *exec.Cmd {
+			serverCmd = exec.Command("sh", "-c", "echo 'listening on 127.0.0.1:1'; sleep 30")
+			return serverCmd
+		},
+		ReadyPattern: readyPattern,
+		ReadyTimeout: 2 * time.Second,
+	}
+
+	if _, err := h.Run(func(addr string) *exec.Cmd { return exec.Command("true") }); err != nil {
+		t.Fatal(err)
+	}
+	// Harness.Run's internal teardown already called Wait(); by the time
+	// Run returns, the server's ProcessState should show it was killed
+	// rather than having exited on its own (its script sleeps for 30s).
+	if serverCmd.ProcessState == nil {
+		t.Fatal("expected the server process to have been reaped by teardown")
+	}
+	if serverCmd.ProcessState.Success() {
+		t.Fatal("expected the server to have been killed, not to exit successfully")
+	}
+}
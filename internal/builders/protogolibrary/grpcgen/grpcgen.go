@@ -0,0 +1,325 @@
+// Package grpcgen generates gRPC service stubs (server interface, a
+// grpc.ServiceDesc-based registration function, and a client) from a
+// parsed .proto file, in the same style protoc-gen-go-grpc's generated
+// code uses. It exists because upstream's protoc-gen-go-grpc module
+// requires a newer Go toolchain than this workspace targets; this covers
+// the unary and streaming method shapes ProtoGoLibrary's "grpc" plugin
+// mode actually needs to support.
+package grpcgen
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+var (
+	contextPkg = protogen.GoImportPath("context")
+	grpcPkg    = protogen.GoImportPath("google.golang.org/grpc")
+	codesPkg   = protogen.GoImportPath("google.golang.org/grpc/codes")
+	statusPkg  = protogen.GoImportPath("google.golang.org/grpc/status")
+)
+
+// GenerateFile emits "<prefix>_grpc.pb.go" for every service declared in
+// file, registering it with gen so it's included in the plugin's response.
+func GenerateFile(gen *protogen.Plugin, file *protogen.File) {
+	if len(file.Services) == 0 {
+		return
+	}
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_grpc.pb.go", file.GoImportPath)
+	g.P("// Code generated by ybt's protogolibrary grpc generator. DO NOT EDIT.")
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+
+	for _, svc := range file.Services {
+		genService(g, svc)
+	}
+}
+
+func genService(g *protogen.GeneratedFile, svc *protogen.Service) {
+	name := svc.GoName
+
+	// Server interface and Unimplemented embed, matching protoc-gen-go-grpc's
+	// forward-compatibility convention.
+	g.P("type ", name, "Server interface {")
+	for _, m := range svc.Methods {
+		g.P(serverMethodSig(g, m))
+	}
+	g.P("mustEmbedUnimplemented", name, "Server()")
+	g.P("}")
+	g.P()
+
+	g.P("type Unimplemented", name, "Server struct {}")
+	g.P()
+	for _, m := range svc.Methods {
+		g.P("func (Unimplemented", name, "Server) ", serverMethodSig(g, m), " {")
+		g.P("return ", unimplementedReturn(g, m))
+		g.P("}")
+	}
+	g.P("func (Unimplemented", name, "Server) mustEmbedUnimplemented", name, "Server() {}")
+	g.P()
+
+	// Registration, via a plain grpc.ServiceDesc the same way generated
+	// code has always done it.
+	g.P("func Register", name, "Server(s grpc.ServiceRegistrar, srv ", name, "Server) {")
+	g.P("s.RegisterService(&", unexported(name), "_ServiceDesc, srv)")
+	g.P("}")
+	g.P()
+
+	genServiceDesc(g, svc)
+	genClient(g, svc)
+
+	for _, m := range svc.Methods {
+		if isStreaming(m) {
+			genStreamTypes(g, svc, m)
+		}
+	}
+}
+
+func isStreaming(m *protogen.Method) bool {
+	return m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer()
+}
+
+func serverMethodSig(g *protogen.GeneratedFile, m *protogen.Method) string {
+	in := g.QualifiedGoIdent(m.Input.GoIdent)
+	out := g.QualifiedGoIdent(m.Output.GoIdent)
+	switch {
+	case m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer():
+		return fmt.Sprintf("%s(stream %s_%sServer) error", m.GoName, m.Parent.GoName, m.GoName)
+	default:
+		return fmt.Sprintf("%s(ctx %s, in *%s) (*%s, error)", m.GoName, g.QualifiedGoIdent(contextPkg.Ident("Context")), in, out)
+	}
+}
+
+func unimplementedReturn(g *protogen.GeneratedFile, m *protogen.Method) string {
+	errIdent := g.QualifiedGoIdent(statusPkg.Ident("Errorf"))
+	codeIdent := g.QualifiedGoIdent(codesPkg.Ident("Unimplemented"))
+	if isStreaming(m) {
+		return fmt.Sprintf("%s(%s, %q)", errIdent, codeIdent, "method "+m.GoName+" not implemented")
+	}
+	return fmt.Sprintf("nil, %s(%s, %q)", errIdent, codeIdent, "method "+m.GoName+" not implemented")
+}
+
+func genServiceDesc(g *protogen.GeneratedFile, svc *protogen.Service) {
+	name := svc.GoName
+	g.P("var ", unexported(name), "_ServiceDesc = ", g.QualifiedGoIdent(grpcPkg.Ident("ServiceDesc")), "{")
+	g.P(`ServiceName: "`, svc.Desc.FullName(), `",`)
+	g.P("HandlerType: (*", name, "Server)(nil),")
+	g.P("Methods: []", g.QualifiedGoIdent(grpcPkg.Ident("MethodDesc")), "{")
+	for _, m := range svc.Methods {
+		if isStreaming(m) {
+			continue
+		}
+		g.P("{")
+		g.P(`MethodName: "`, m.GoName, `",`)
+		g.P("Handler: ", unexported(name), "_", m.GoName, "_Handler,")
+		g.P("},")
+	}
+	g.P("},")
+	g.P("Streams: []", g.QualifiedGoIdent(grpcPkg.Ident("StreamDesc")), "{")
+	for _, m := range svc.Methods {
+		if !isStreaming(m) {
+			continue
+		}
+		g.P("{")
+		g.P(`StreamName: "`, m.GoName, `",`)
+		g.P("Handler: ", unexported(name), "_", m.GoName, "_Handler,")
+		g.P("ServerStreams: ", m.Desc.IsStreamingServer(), ",")
+		g.P("ClientStreams: ", m.Desc.IsStreamingClient(), ",")
+		g.P("},")
+	}
+	g.P("},")
+	g.P("}")
+	g.P()
+
+	for _, m := range svc.Methods {
+		genHandler(g, svc, m)
+	}
+}
+
+func genHandler(g *protogen.GeneratedFile, svc *protogen.Service, m *protogen.Method) {
+	name := svc.GoName
+	in := g.QualifiedGoIdent(m.Input.GoIdent)
+	if isStreaming(m) {
+		g.P("func ", unexported(name), "_", m.GoName, "_Handler(srv interface{}, stream ", g.QualifiedGoIdent(grpcPkg.Ident("ServerStream")), ") error {")
+		g.P("return srv.(", name, "Server).", m.GoName, "(&", streamImplName(name, m.GoName, "Server"), "{stream})")
+		g.P("}")
+		g.P()
+		return
+	}
+	g.P("func ", unexported(name), "_", m.GoName, "_Handler(srv interface{}, ctx ", g.QualifiedGoIdent(contextPkg.Ident("Context")), ", dec func(interface{}) error, interceptor ", g.QualifiedGoIdent(grpcPkg.Ident("UnaryServerInterceptor")), ") (interface{}, error) {")
+	g.P("in := new(", in, ")")
+	g.P("if err := dec(in); err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("if interceptor == nil {")
+	g.P("return srv.(", name, "Server).", m.GoName, "(ctx, in)")
+	g.P("}")
+	g.P("info := &", g.QualifiedGoIdent(grpcPkg.Ident("UnaryServerInfo")), "{Server: srv, FullMethod: \"/", svc.Desc.FullName(), "/", m.GoName, "\"}")
+	g.P("handler := func(ctx ", g.QualifiedGoIdent(contextPkg.Ident("Context")), ", req interface{}) (interface{}, error) {")
+	g.P("return srv.(", name, "Server).", m.GoName, "(ctx, req.(*", in, "))")
+	g.P("}")
+	g.P("return interceptor(ctx, in, info, handler)")
+	g.P("}")
+	g.P()
+}
+
+func genClient(g *protogen.GeneratedFile, svc *protogen.Service) {
+	name := svc.GoName
+	g.P("type ", name, "Client interface {")
+	for _, m := range svc.Methods {
+		g.P(clientMethodSig(g, m))
+	}
+	g.P("}")
+	g.P()
+
+	g.P("type ", unexported(name), "Client struct {")
+	g.P("cc ", g.QualifiedGoIdent(grpcPkg.Ident("ClientConnInterface")))
+	g.P("}")
+	g.P()
+	g.P("func New", name, "Client(cc ", g.QualifiedGoIdent(grpcPkg.Ident("ClientConnInterface")), ") ", name, "Client {")
+	g.P("return &", unexported(name), "Client{cc}")
+	g.P("}")
+	g.P()
+
+	for _, m := range svc.Methods {
+		genClientMethod(g, svc, m)
+	}
+}
+
+func clientMethodSig(g *protogen.GeneratedFile, m *protogen.Method) string {
+	out := g.QualifiedGoIdent(m.Output.GoIdent)
+	in := g.QualifiedGoIdent(m.Input.GoIdent)
+	if isStreaming(m) {
+		return fmt.Sprintf("%s(ctx %s, opts ...%s) (%s_%sClient, error)",
+			m.GoName, g.QualifiedGoIdent(contextPkg.Ident("Context")), g.QualifiedGoIdent(grpcPkg.Ident("CallOption")), m.Parent.GoName, m.GoName)
+	}
+	return fmt.Sprintf("%s(ctx %s, in *%s, opts ...%s) (*%s, error)",
+		m.GoName, g.QualifiedGoIdent(contextPkg.Ident("Context")), in, g.QualifiedGoIdent(grpcPkg.Ident("CallOption")), out)
+}
+
+func genClientMethod(g *protogen.GeneratedFile, svc *protogen.Service, m *protogen.Method) {
+	name := svc.GoName
+	recv := unexported(name) + "Client"
+	fullMethod := "/" + string(svc.Desc.FullName()) + "/" + m.GoName
+	if isStreaming(m) {
+		g.P("func (c *", recv, ") ", clientMethodSig(g, m), " {")
+		g.P("stream, err := c.cc.NewStream(ctx, &", unexported(name), "_ServiceDesc.Streams[", streamIndex(svc, m), "], \"", fullMethod, "\", opts...)")
+		g.P("if err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("return &", streamImplName(name, m.GoName, "Client"), "{stream}, nil")
+		g.P("}")
+		g.P()
+		return
+	}
+	out := g.QualifiedGoIdent(m.Output.GoIdent)
+	g.P("func (c *", recv, ") ", clientMethodSig(g, m), " {")
+	g.P("out := new(", out, ")")
+	g.P("err := c.cc.Invoke(ctx, \"", fullMethod, "\", in, out, opts...)")
+	g.P("if err != nil {")
+	g.P("return nil, err")
+	g.P("}")
+	g.P("return out, nil")
+	g.P("}")
+	g.P()
+}
+
+func streamIndex(svc *protogen.Service, m *protogen.Method) int {
+	i := 0
+	for _, c := range svc.Methods {
+		if !isStreaming(c) {
+			continue
+		}
+		if c == m {
+			return i
+		}
+		i++
+	}
+	return 0
+}
+
+// genStreamTypes emits the <Service>_<Method>Client / <Service>_<Method>Server
+// wrapper interfaces around grpc.ClientStream/ServerStream, typed to this
+// method's request/response messages, along with their concrete
+// implementations. The concrete types are unexported (streamImplName)
+// since only the interfaces are part of the generated API, matching
+// upstream protoc-gen-go-grpc's convention; giving the struct the same
+// name as the interface would be a redeclaration.
+func genStreamTypes(g *protogen.GeneratedFile, svc *protogen.Service, m *protogen.Method) {
+	name := svc.GoName
+	in := g.QualifiedGoIdent(m.Input.GoIdent)
+	out := g.QualifiedGoIdent(m.Output.GoIdent)
+
+	if m.Desc.IsStreamingServer() {
+		clientImpl := streamImplName(name, m.GoName, "Client")
+		g.P("type ", name, "_", m.GoName, "Client interface {")
+		g.P("Recv() (*", out, ", error)")
+		if m.Desc.IsStreamingClient() {
+			g.P("Send(*", in, ") error")
+			g.P("CloseSend() error")
+		}
+		g.P(g.QualifiedGoIdent(grpcPkg.Ident("ClientStream")))
+		g.P("}")
+		g.P()
+		g.P("type ", clientImpl, " struct {")
+		g.P(g.QualifiedGoIdent(grpcPkg.Ident("ClientStream")))
+		g.P("}")
+		g.P()
+		g.P("func (x *", clientImpl, ") Recv() (*", out, ", error) {")
+		g.P("m := new(", out, ")")
+		g.P("if err := x.ClientStream.RecvMsg(m); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("return m, nil")
+		g.P("}")
+		if m.Desc.IsStreamingClient() {
+			g.P("func (x *", clientImpl, ") Send(msg *", in, ") error {")
+			g.P("return x.ClientStream.SendMsg(msg)")
+			g.P("}")
+		}
+		g.P()
+	}
+
+	serverImpl := streamImplName(name, m.GoName, "Server")
+	g.P("type ", name, "_", m.GoName, "Server interface {")
+	if m.Desc.IsStreamingClient() {
+		g.P("Recv() (*", in, ", error)")
+	}
+	g.P("Send(*", out, ") error")
+	g.P(g.QualifiedGoIdent(grpcPkg.Ident("ServerStream")))
+	g.P("}")
+	g.P()
+	g.P("type ", serverImpl, " struct {")
+	g.P(g.QualifiedGoIdent(grpcPkg.Ident("ServerStream")))
+	g.P("}")
+	g.P()
+	if m.Desc.IsStreamingClient() {
+		g.P("func (x *", serverImpl, ") Recv() (*", in, ", error) {")
+		g.P("m := new(", in, ")")
+		g.P("if err := x.ServerStream.RecvMsg(m); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P("return m, nil")
+		g.P("}")
+	}
+	g.P("func (x *", serverImpl, ") Send(msg *", out, ") error {")
+	g.P("return x.ServerStream.SendMsg(msg)")
+	g.P("}")
+	g.P()
+}
+
+// streamImplName returns the unexported concrete type name backing the
+// exported <Service>_<Method><Side> stream interface (side is "Client" or
+// "Server").
+func streamImplName(svcName, methodName, side string) string {
+	return unexported(svcName) + methodName + side
+}
+
+func unexported(s string) string {
+	if s == "" {
+		return s
+	}
+	return string(s[0]|0x20) + s[1:]
+}
@@ -0,0 +1,257 @@
+// Package protogolibrary implements the ProtoGoLibrary target type. It has
+// two ways to drive codegen: Generate compiles .proto sources to .pb.go
+// content in-process, with no protoc binary required, and is what ybt's
+// own build/test commands use; ProtocCmd/PluginMain instead re-exec the
+// ybt binary as protoc's --plugin, for workspaces that do have protoc
+// installed and want protoc's own import/well-known-types handling.
+// Either path runs the same protoc-gen-go codegen upstream uses, and,
+// when Plugins=="grpc", ybt's own grpc stub generator (see the grpcgen
+// subpackage, written for this workspace's Go toolchain since upstream's
+// protoc-gen-go-grpc requires a newer one).
+package protogolibrary
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"resonai/ybt/internal/builders/protogolibrary/grpcgen"
+	"resonai/ybt/internal/target"
+)
+
+// RunAsProtocPluginEnv is set by Library.ProtocCmd on the re-exec'd child
+// process to tell main() to behave as a protoc plugin (reading a
+// CodeGeneratorRequest from stdin) instead of running the normal ybt CLI.
+const RunAsProtocPluginEnv = "YBT_RUN_AS_PROTOC_PLUGIN"
+
+// pluginGoName and pluginGoGrpcName are the argv[0] basenames ProtocCmd
+// invokes the re-exec'd binary under, so PluginMain can tell which of the
+// two roles (protoc-gen-go vs protoc-gen-go-grpc) it's standing in for:
+// protoc itself just execs whatever path a --plugin flag names, with no
+// other indication of which plugin it thinks it's running, so the only
+// way to distinguish the two is to point the flags at differently-named
+// copies of the same binary.
+const (
+	pluginGoName     = "protoc-gen-go"
+	pluginGoGrpcName = "protoc-gen-go-grpc"
+)
+
+// Library describes a ProtoGoLibrary target.
+type Library struct {
+	Name         string
+	Srcs         []string // .proto sources, relative to the target's package dir
+	GoImportPath string
+	Deps         []string // other ProtoGoLibrary / GoLibrary targets
+	// Plugins selects the protoc-gen-go variant to run: "" for the plain
+	// message/enum generator, "grpc" to additionally emit gRPC stubs.
+	Plugins string
+	// PathsSourceRelative mirrors protoc's paths=source_relative option:
+	// output file layout matches the .proto's own directory instead of
+	// being rooted at GoImportPath.
+	PathsSourceRelative bool
+}
+
+// PluginMain runs the current process as a protoc plugin: it reads a
+// CodeGeneratorRequest from stdin and writes a CodeGeneratorResponse to
+// stdout. It's invoked by main() when RunAsProtocPluginEnv is set, after
+// Library.ProtocCmd re-execs the ybt binary as one of protoc's --plugin
+// entries. Which plugin it behaves as is decided by argv[0]: protoc
+// doesn't tell a plugin which --plugin flag invoked it, so ProtocCmd
+// arranges for the two roles to run under different binary names.
+func PluginMain() {
+	grpc := filepath.Base(os.Args[0]) == pluginGoGrpcName
+	(protogen.Options{}).Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = gengo.SupportedFeatures
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			if grpc {
+				grpcgen.GenerateFile(gen, f)
+			} else {
+				gengo.GenerateFile(gen, f)
+			}
+		}
+		return nil
+	})
+}
+
+// ProtocCmd builds the protoc invocation for this library. selfPath is the
+// path to the currently-running ybt binary. binDir is a directory ProtocCmd
+// may place pluginGoName/pluginGoGrpcName-named symlinks to selfPath in, so
+// protoc invokes the same binary under names PluginMain can tell apart;
+// outDir is the cache directory the generated .pb.go files are staged
+// into.
+func (l *Library) ProtocCmd(selfPath, binDir, outDir string) (*exec.Cmd, error) {
+	goPlugin, err := pluginSymlink(binDir, pluginGoName, selfPath)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{
+		"--plugin=protoc-gen-go=" + goPlugin,
+		"--go_out=" + outDir,
+	}
+	if l.PathsSourceRelative {
+		args = append(args, "--go_opt=paths=source_relative")
+	} else {
+		args = append(args, "--go_opt=paths=import")
+	}
+	if l.Plugins == "grpc" {
+		grpcPlugin, err := pluginSymlink(binDir, pluginGoGrpcName, selfPath)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args,
+			"--plugin=protoc-gen-go-grpc="+grpcPlugin,
+			"--go-grpc_out="+outDir,
+			"--go-grpc_opt=paths="+pathsMode(l.PathsSourceRelative),
+		)
+	}
+	args = append(args, l.Srcs...)
+
+	cmd := exec.Command("protoc", args...)
+	cmd.Env = append(os.Environ(), RunAsProtocPluginEnv+"=1")
+	return cmd, nil
+}
+
+// pluginSymlink ensures binDir/name exists as a symlink to target,
+// creating it if necessary, and returns its path.
+func pluginSymlink(binDir, name, target string) (string, error) {
+	path := filepath.Join(binDir, name)
+	if _, err := os.Lstat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Symlink(target, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Generate compiles this library's .proto sources in-process, with no
+// protoc binary required, using the pure-Go protoparse compiler, and runs
+// them through the same protoc-gen-go codegen PluginMain uses (plus
+// ybt's own grpc stub generator when Plugins=="grpc"). importRoot is the
+// workspace directory .proto import statements are resolved relative to;
+// pkgDir is this library's own package directory, used to compute its
+// Srcs' paths relative to importRoot. It returns generated file content
+// keyed by the path protoc-gen-go assigned it (e.g. "proto/greeter/greeter.pb.go").
+func (l *Library) Generate(importRoot, pkgDir string) (map[string][]byte, error) {
+	relDir, err := filepath.Rel(importRoot, pkgDir)
+	if err != nil {
+		return nil, fmt.Errorf("protogolibrary: %s is not under import root %s: %w", pkgDir, importRoot, err)
+	}
+	relSrcs := make([]string, 0, len(l.Srcs))
+	for _, src := range l.Srcs {
+		relSrcs = append(relSrcs, filepath.ToSlash(filepath.Join(relDir, src)))
+	}
+
+	parser := protoparse.Parser{ImportPaths: []string{importRoot}}
+	fds, err := parser.ParseFiles(relSrcs...)
+	if err != nil {
+		return nil, fmt.Errorf("protogolibrary: parsing %v: %w", relSrcs, err)
+	}
+
+	var protoFiles []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	var addAll func(fd *desc.FileDescriptor)
+	addAll = func(fd *desc.FileDescriptor) {
+		for _, dep := range fd.GetDependencies() {
+			addAll(dep)
+		}
+		if !seen[fd.GetName()] {
+			seen[fd.GetName()] = true
+			protoFiles = append(protoFiles, fd.AsFileDescriptorProto())
+		}
+	}
+	for _, fd := range fds {
+		addAll(fd)
+	}
+
+	param := "paths=" + pathsMode(l.PathsSourceRelative)
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: relSrcs,
+		Parameter:      proto.String(param),
+		ProtoFile:      protoFiles,
+	}
+	gen, err := (protogen.Options{}).New(req)
+	if err != nil {
+		return nil, fmt.Errorf("protogolibrary: %w", err)
+	}
+	gen.SupportedFeatures = gengo.SupportedFeatures
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		gengo.GenerateFile(gen, f)
+		if l.Plugins == "grpc" {
+			grpcgen.GenerateFile(gen, f)
+		}
+	}
+
+	resp := gen.Response()
+	if resp.Error != nil {
+		return nil, fmt.Errorf("protogolibrary: %s", resp.GetError())
+	}
+	out := map[string][]byte{}
+	for _, f := range resp.File {
+		out[f.GetName()] = []byte(f.GetContent())
+	}
+	return out, nil
+}
+
+func pathsMode(sourceRelative bool) string {
+	if sourceRelative {
+		return "source_relative"
+	}
+	return "import"
+}
+
+// GeneratedFiles returns the paths, joined onto outDir, of the files
+// protoc will emit for each .proto source given this library's paths
+// mode: its .pb.go file and, when Plugins=="grpc", its _grpc.pb.go file.
+func (l *Library) GeneratedFiles(outDir string) []string {
+	out := make([]string, 0, len(l.Srcs))
+	for _, src := range l.Srcs {
+		base := strings.TrimSuffix(src, ".proto") + ".pb.go"
+		if l.PathsSourceRelative {
+			out = append(out, filepath.Join(outDir, base))
+		} else {
+			out = append(out, filepath.Join(outDir, l.GoImportPath, filepath.Base(base)))
+		}
+		if l.Plugins == "grpc" {
+			grpcBase := strings.TrimSuffix(src, ".proto") + "_grpc.pb.go"
+			if l.PathsSourceRelative {
+				out = append(out, filepath.Join(outDir, grpcBase))
+			} else {
+				out = append(out, filepath.Join(outDir, l.GoImportPath, filepath.Base(grpcBase)))
+			}
+		}
+	}
+	return out
+}
+
+// CacheKey hashes every attribute that affects this library's generated Go
+// sources, so downstream GoProgram/GoTest targets that depend on it pick up
+// a fresh build whenever a .proto source, the import path, or the plugin
+// mode changes.
+func (l *Library) CacheKey() *target.CacheKey {
+	return target.NewCacheKey().
+		Add("srcs", l.Srcs...).
+		Add("go_import_path", l.GoImportPath).
+		Add("plugins", l.Plugins).
+		Add("paths_source_relative", fmt.Sprint(l.PathsSourceRelative)).
+		AddSorted("deps", l.Deps...)
+}
@@ -0,0 +1,117 @@
+package protogolibrary
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProtocCmdPlainMode(t *testing.T) {
+	l := &Library{
+		Name:         "base_protos",
+		Srcs:         []string{"base.proto"},
+		GoImportPath: "proto/base_protos",
+	}
+	binDir := t.TempDir()
+	cmd, err := l.ProtocCmd("/path/to/ybt", binDir, "/cache/out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goPlugin := filepath.Join(binDir, pluginGoName)
+
+	want := []string{
+		"--plugin=protoc-gen-go=" + goPlugin,
+		"--go_out=/cache/out",
+		"--go_opt=paths=import",
+		"base.proto",
+	}
+	if got := cmd.Args[1:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ProtocCmd args = %v, want %v", got, want)
+	}
+	if cmd.Path == "" || cmd.Args[0] != "protoc" {
+		t.Fatalf("expected protoc to be the invoked binary, got %q", cmd.Args[0])
+	}
+	if target, err := os.Readlink(goPlugin); err != nil || target != "/path/to/ybt" {
+		t.Fatalf("expected %s to symlink to /path/to/ybt, got %q, %v", goPlugin, target, err)
+	}
+
+	found := false
+	for _, e := range cmd.Env {
+		if e == RunAsProtocPluginEnv+"=1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s=1 in child env, got %v", RunAsProtocPluginEnv, cmd.Env)
+	}
+}
+
+func TestProtocCmdGrpcMode(t *testing.T) {
+	l := &Library{
+		Srcs:                []string{"greeter.proto"},
+		GoImportPath:        "proto/greeter",
+		Plugins:             "grpc",
+		PathsSourceRelative: true,
+	}
+	binDir := t.TempDir()
+	cmd, err := l.ProtocCmd("/path/to/ybt", binDir, "/cache/out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goPlugin := filepath.Join(binDir, pluginGoName)
+	grpcPlugin := filepath.Join(binDir, pluginGoGrpcName)
+
+	want := []string{
+		"--plugin=protoc-gen-go=" + goPlugin,
+		"--go_out=/cache/out",
+		"--go_opt=paths=source_relative",
+		"--plugin=protoc-gen-go-grpc=" + grpcPlugin,
+		"--go-grpc_out=/cache/out",
+		"--go-grpc_opt=paths=source_relative",
+		"greeter.proto",
+	}
+	if got := cmd.Args[1:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ProtocCmd args = %v, want %v", got, want)
+	}
+	if target, err := os.Readlink(grpcPlugin); err != nil || target != "/path/to/ybt" {
+		t.Fatalf("expected %s to symlink to /path/to/ybt, got %q, %v", grpcPlugin, target, err)
+	}
+}
+
+func TestGeneratedFilesSourceRelative(t *testing.T) {
+	l := &Library{
+		Srcs:                []string{"base.proto"},
+		GoImportPath:        "proto/base_protos",
+		PathsSourceRelative: true,
+	}
+	got := l.GeneratedFiles("/cache/out")
+	want := []string{"/cache/out/base.pb.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GeneratedFiles = %v, want %v", got, want)
+	}
+}
+
+func TestGeneratedFilesGrpc(t *testing.T) {
+	l := &Library{
+		Srcs:         []string{"greeter.proto"},
+		GoImportPath: "proto/greeter",
+		Plugins:      "grpc",
+	}
+	got := l.GeneratedFiles("/cache/out")
+	want := []string{
+		"/cache/out/proto/greeter/greeter.pb.go",
+		"/cache/out/proto/greeter/greeter_grpc.pb.go",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GeneratedFiles = %v, want %v", got, want)
+	}
+}
+
+func TestCacheKeyChangesWithPlugins(t *testing.T) {
+	base := &Library{Srcs: []string{"a.proto"}, GoImportPath: "a"}
+	grpc := &Library{Srcs: []string{"a.proto"}, GoImportPath: "a", Plugins: "grpc"}
+	if base.CacheKey().String() == grpc.CacheKey().String() {
+		t.Fatal("expected cache key to change when Plugins changes")
+	}
+}
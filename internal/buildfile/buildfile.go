@@ -0,0 +1,286 @@
+// Package buildfile parses BUILD files into target declarations. BUILD
+// files in this workspace are a small, deliberately non-Turing-complete
+// subset of Starlark: a sequence of top-level rule calls with keyword
+// arguments whose values are string/bool literals, lists, or dicts of the
+// same. There is no variable binding, no control flow, and no user
+// functions, so a recursive-descent parser over Go's own tokenizer is
+// enough; we don't need a full Starlark interpreter to evaluate them.
+package buildfile
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+)
+
+// Kind identifies which Go type a Value actually holds.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindList
+	KindDict
+)
+
+// Value is a single attribute value: a string, a bool, a list of Values, or
+// a string-keyed dict of Values, matching the literal forms BUILD files use.
+type Value struct {
+	Kind Kind
+	Str  string
+	Bool bool
+	List []Value
+	Dict map[string]Value
+}
+
+// AsString returns the value's string, or "" if it isn't a string.
+func (v Value) AsString() string {
+	if v.Kind != KindString {
+		return ""
+	}
+	return v.Str
+}
+
+// AsStringList returns a list value's elements as strings, or nil if v
+// isn't a list.
+func (v Value) AsStringList() []string {
+	if v.Kind != KindList {
+		return nil
+	}
+	out := make([]string, 0, len(v.List))
+	for _, e := range v.List {
+		out = append(out, e.AsString())
+	}
+	return out
+}
+
+// AsDict returns a dict value's entries, or nil if v isn't a dict.
+func (v Value) AsDict() map[string]Value {
+	if v.Kind != KindDict {
+		return nil
+	}
+	return v.Dict
+}
+
+// Target is one top-level rule call, e.g. go_test(name = "...", srcs = [...]).
+type Target struct {
+	Rule  string
+	Attrs map[string]Value
+}
+
+// Attr returns t.Attrs[name], or the zero Value if it's unset.
+func (t Target) Attr(name string) Value {
+	return t.Attrs[name]
+}
+
+// Parse parses the contents of a single BUILD file into its target calls.
+func Parse(src []byte) ([]Target, error) {
+	src = stripHashComments(src)
+	p := &parser{}
+	var fset token.FileSet
+	file := fset.AddFile("BUILD", fset.Base(), len(src))
+	p.s.Init(file, src, func(pos token.Position, msg string) {
+		if p.err == nil {
+			p.err = fmt.Errorf("%s: %s", pos, msg)
+		}
+	}, scanner.ScanComments)
+
+	var targets []Target
+	p.next()
+	for p.tok != token.EOF {
+		if p.err != nil {
+			return nil, p.err
+		}
+		if p.tok == token.COMMENT {
+			p.next()
+			continue
+		}
+		t, err := p.parseTarget()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, p.err
+}
+
+type parser struct {
+	s       scanner.Scanner
+	err     error
+	pos     token.Pos
+	tok     token.Token
+	lit     string
+}
+
+// next advances to the next significant token. Comments are dropped, and
+// so are the semicolons Go's scanner auto-inserts at line breaks: BUILD
+// files have no statements to terminate, so those semicolons carry no
+// meaning here.
+func (p *parser) next() {
+	for {
+		p.pos, p.tok, p.lit = p.s.Scan()
+		if p.tok != token.COMMENT && p.tok != token.SEMICOLON {
+			return
+		}
+	}
+}
+
+func (p *parser) expect(tok token.Token) error {
+	if p.tok != tok {
+		return fmt.Errorf("buildfile: expected %s, got %s %q", tok, p.tok, p.lit)
+	}
+	p.next()
+	return nil
+}
+
+// parseTarget parses `rule_name(key = value, key = value, ...)`.
+func (p *parser) parseTarget() (Target, error) {
+	if p.tok != token.IDENT {
+		return Target{}, fmt.Errorf("buildfile: expected a rule name, got %s %q", p.tok, p.lit)
+	}
+	rule := p.lit
+	p.next()
+	if err := p.expect(token.LPAREN); err != nil {
+		return Target{}, err
+	}
+
+	attrs := map[string]Value{}
+	for p.tok != token.RPAREN {
+		if p.tok != token.IDENT {
+			return Target{}, fmt.Errorf("buildfile: expected an attribute name, got %s %q", p.tok, p.lit)
+		}
+		name := p.lit
+		p.next()
+		if err := p.expect(token.ASSIGN); err != nil {
+			return Target{}, err
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return Target{}, err
+		}
+		attrs[name] = v
+		if p.tok == token.COMMA {
+			p.next()
+			continue
+		}
+		break
+	}
+	if err := p.expect(token.RPAREN); err != nil {
+		return Target{}, err
+	}
+	return Target{Rule: rule, Attrs: attrs}, nil
+}
+
+func (p *parser) parseValue() (Value, error) {
+	switch p.tok {
+	case token.STRING:
+		s, err := unquote(p.lit)
+		if err != nil {
+			return Value{}, err
+		}
+		p.next()
+		return Value{Kind: KindString, Str: s}, nil
+	case token.IDENT:
+		switch p.lit {
+		case "True", "False":
+			b := p.lit == "True"
+			p.next()
+			return Value{Kind: KindBool, Bool: b}, nil
+		}
+		return Value{}, fmt.Errorf("buildfile: unexpected identifier %q", p.lit)
+	case token.LBRACK:
+		return p.parseList()
+	case token.LBRACE:
+		return p.parseDict()
+	default:
+		return Value{}, fmt.Errorf("buildfile: unexpected token %s %q", p.tok, p.lit)
+	}
+}
+
+func (p *parser) parseList() (Value, error) {
+	if err := p.expect(token.LBRACK); err != nil {
+		return Value{}, err
+	}
+	var items []Value
+	for p.tok != token.RBRACK {
+		v, err := p.parseValue()
+		if err != nil {
+			return Value{}, err
+		}
+		items = append(items, v)
+		if p.tok == token.COMMA {
+			p.next()
+			continue
+		}
+		break
+	}
+	if err := p.expect(token.RBRACK); err != nil {
+		return Value{}, err
+	}
+	return Value{Kind: KindList, List: items}, nil
+}
+
+func (p *parser) parseDict() (Value, error) {
+	if err := p.expect(token.LBRACE); err != nil {
+		return Value{}, err
+	}
+	dict := map[string]Value{}
+	for p.tok != token.RBRACE {
+		if p.tok != token.STRING {
+			return Value{}, fmt.Errorf("buildfile: expected a string dict key, got %s %q", p.tok, p.lit)
+		}
+		key, err := unquote(p.lit)
+		if err != nil {
+			return Value{}, err
+		}
+		p.next()
+		if err := p.expect(token.COLON); err != nil {
+			return Value{}, err
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return Value{}, err
+		}
+		dict[key] = v
+		if p.tok == token.COMMA {
+			p.next()
+			continue
+		}
+		break
+	}
+	if err := p.expect(token.RBRACE); err != nil {
+		return Value{}, err
+	}
+	return Value{Kind: KindDict, Dict: dict}, nil
+}
+
+// stripHashComments blanks out '#'-to-end-of-line comments, BUILD files'
+// comment syntax, while leaving line/column positions intact (so parser
+// error messages keep pointing at the original source) and leaving any
+// '#' inside a string literal untouched.
+func stripHashComments(src []byte) []byte {
+	out := append([]byte(nil), src...)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			inString = !inString
+		case '#':
+			if inString {
+				continue
+			}
+			for i < len(out) && out[i] != '\n' {
+				out[i] = ' '
+				i++
+			}
+		}
+	}
+	return out
+}
+
+func unquote(lit string) (string, error) {
+	if len(lit) < 2 {
+		return "", fmt.Errorf("buildfile: malformed string literal %q", lit)
+	}
+	return lit[1 : len(lit)-1], nil
+}
@@ -0,0 +1,66 @@
+package buildfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRulesWithListsDictsAndBools(t *testing.T) {
+	src := `
+go_library(
+    name = "hello_lib",
+    srcs = ["utils.go"],
+    importpath = "bar.com/hello_lib",
+    auto_imports = True,
+)
+
+go_golden_test(
+    name = "hello_golden_test",
+    program = ":hello",
+    cases = {
+        "default": {"argv": []},
+        "boomer": {"argv": ["-who=boomer"]},
+    },
+)
+`
+	targets, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(targets))
+	}
+
+	lib := targets[0]
+	if lib.Rule != "go_library" {
+		t.Fatalf("Rule = %q, want go_library", lib.Rule)
+	}
+	if got := lib.Attr("name").AsString(); got != "hello_lib" {
+		t.Fatalf("name = %q, want hello_lib", got)
+	}
+	if got := lib.Attr("srcs").AsStringList(); !reflect.DeepEqual(got, []string{"utils.go"}) {
+		t.Fatalf("srcs = %v", got)
+	}
+	if got := lib.Attr("auto_imports").Bool; !got {
+		t.Fatal("auto_imports = false, want true")
+	}
+
+	golden := targets[1]
+	cases := golden.Attr("cases").AsDict()
+	if len(cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(cases))
+	}
+	boomer := cases["boomer"].AsDict()
+	if got := boomer["argv"].AsStringList(); !reflect.DeepEqual(got, []string{"-who=boomer"}) {
+		t.Fatalf("boomer argv = %v", got)
+	}
+	if got := cases["default"].AsDict()["argv"].AsStringList(); len(got) != 0 {
+		t.Fatalf("default argv = %v, want empty", got)
+	}
+}
+
+func TestParseRejectsMalformedInput(t *testing.T) {
+	if _, err := Parse([]byte(`go_library(name = "x"`)); err == nil {
+		t.Fatal("expected an error for an unclosed call")
+	}
+}
@@ -0,0 +1,551 @@
+// Package execute turns a go_test/go_program/go_grpc_service/
+// grpc_integration_test label into an actual compiled, runnable binary: it
+// materializes the target's transitive dependency closure (go_library
+// packages copied verbatim, proto_go_library packages run through
+// protogolibrary.Library.Generate, and this workspace's external modules
+// pinned to the root go.mod's own versions) into a self-contained Go
+// module overlay in a temp directory, then drives the standard go tool
+// against it. This is what lets ybt's test/golden-test commands actually
+// build and run fixture code, instead of only tracking which attributes
+// would affect the result.
+package execute
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"resonai/ybt/internal/builders/gotest"
+	"resonai/ybt/internal/builders/grpcservice"
+	"resonai/ybt/internal/builders/protogolibrary"
+	"resonai/ybt/internal/workspace"
+)
+
+// rootImportPath is the synthetic import path the target actually being
+// built is materialized under. Each overlay only ever builds one root at
+// a time, so a single fixed path is enough to avoid collisions with the
+// real, BUILD-declared import paths of its dependencies.
+const rootImportPath = "ybt.overlay/root"
+
+// externalModule pins one of this workspace's external Go dependencies to
+// the exact version the root go.mod already resolves, so the overlay's own
+// go.mod can be satisfied entirely from the local module cache, without a
+// network fetch.
+type externalModule struct {
+	path    string
+	version string
+}
+
+// externalModules must stay in sync with the root go.mod's own require
+// versions: it exists so overlay builds resolve these same external
+// modules offline, from whatever the local module cache already holds.
+var externalModules = []externalModule{
+	{"github.com/common-nighthawk/go-figure", "v0.0.0-20210622060536-734e95fb86be"},
+	{"github.com/golang/protobuf", "v1.5.4"},
+	{"github.com/stretchr/testify", "v1.11.1"},
+	{"google.golang.org/protobuf", "v1.34.2"},
+	{"google.golang.org/grpc", "v1.64.1"},
+}
+
+// BuildProgram compiles the go_program or go_grpc_service named by label
+// into a standalone binary and returns its path, along with a cleanup func
+// the caller must run once it's done with the binary.
+func BuildProgram(ws *workspace.Workspace, label string) (binPath string, cleanup func(), err error) {
+	e, ok := ws.Entries[label]
+	if !ok {
+		return "", nil, fmt.Errorf("execute: no such target %q", label)
+	}
+	if e.Target.Rule != "go_program" && e.Target.Rule != "go_grpc_service" {
+		return "", nil, fmt.Errorf("execute: %s is a %s, not a go_program or go_grpc_service", label, e.Target.Rule)
+	}
+
+	o, err := newOverlay()
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(o.dir) }
+
+	if err := o.addDepClosure(ws, resolveLabels(label, e.Target.Attr("deps").AsStringList())); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if err := o.addLocalPackage(rootImportPath, e.Dir, e.Target.Attr("srcs").AsStringList(), nil); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if err := o.writeGoMod(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	binDir := filepath.Join(o.dir, "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	binPath = filepath.Join(binDir, e.Target.Attr("name").AsString())
+	cmd := o.goCmd("build", "-o", binPath, rootImportPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("execute: building %s: %w\n%s", label, err, out)
+	}
+	return binPath, cleanup, nil
+}
+
+// RunProgram builds the go_program or go_grpc_service named by label and
+// runs it once with argv, returning its combined stdout+stderr.
+func RunProgram(ws *workspace.Workspace, label string, argv []string) (string, error) {
+	bin, cleanup, err := BuildProgram(ws, label)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	out, err := exec.Command(bin, argv...).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("execute: running %s: %w\n%s", label, err, out)
+	}
+	return string(out), nil
+}
+
+// RunTest compiles and runs the go_test named by label via `go test`,
+// applying t's TestArgs/TestEnv and pulling in its Setup/Teardown packages
+// (see gotest.Test), and reports whether it passed.
+func RunTest(ws *workspace.Workspace, label string, t *gotest.Test) (output string, passed bool, err error) {
+	e, ok := ws.Entries[label]
+	if !ok {
+		return "", false, fmt.Errorf("execute: no such target %q", label)
+	}
+
+	o, err := newOverlay()
+	if err != nil {
+		return "", false, err
+	}
+	defer os.RemoveAll(o.dir)
+
+	deps := resolveLabels(label, e.Target.Attr("deps").AsStringList())
+	if lib := e.Target.Attr("library").AsString(); lib != "" {
+		deps = append(deps, workspace.Resolve(label, lib))
+	}
+
+	// A dep in the test's own directory (whether named via deps or the
+	// library attribute, as goodbye_test does) is the package under
+	// test, sharing this test's own package rather than being a
+	// separate import: its sources are compiled in alongside the
+	// test's, not materialized as a dependency.
+	srcs := append([]string(nil), t.Srcs...)
+	var importDeps []string
+	for _, depLabel := range deps {
+		depEntry, ok := ws.Entries[depLabel]
+		if !ok {
+			return "", false, fmt.Errorf("execute: %s: no such dep %q", label, depLabel)
+		}
+		if depEntry.Dir == e.Dir && (depEntry.Target.Rule == "go_library" || depEntry.Target.Rule == "go_program") {
+			srcs = append(srcs, depEntry.Target.Attr("srcs").AsStringList()...)
+			importDeps = append(importDeps, resolveLabels(depLabel, depEntry.Target.Attr("deps").AsStringList())...)
+			continue
+		}
+		importDeps = append(importDeps, depLabel)
+	}
+	if err := o.addDepClosure(ws, importDeps); err != nil {
+		return "", false, err
+	}
+
+	helpers := append(append([]string(nil), t.Setup...), t.Teardown...)
+	if err := o.addDepClosure(ws, helpers); err != nil {
+		return "", false, err
+	}
+
+	extraFiles := map[string]string{}
+	if len(helpers) > 0 {
+		shim, err := setupShim(ws, e.Dir, srcs, helpers)
+		if err != nil {
+			return "", false, err
+		}
+		extraFiles["ybt_setup_shim.go"] = shim
+	}
+
+	if err := o.addLocalPackage(rootImportPath, e.Dir, srcs, extraFiles); err != nil {
+		return "", false, err
+	}
+	if err := o.writeGoMod(); err != nil {
+		return "", false, err
+	}
+
+	args := append([]string{"test", rootImportPath}, "-args")
+	args = append(args, t.Argv()...)
+	cmd := o.goCmd(args...)
+	cmd.Env = append(cmd.Env, t.Env()...)
+	out, runErr := cmd.CombinedOutput()
+	if runErr == nil {
+		return string(out), true, nil
+	}
+	if _, ok := runErr.(*exec.ExitError); ok {
+		return string(out), false, nil
+	}
+	return string(out), false, fmt.Errorf("execute: running %s: %w", label, runErr)
+}
+
+// readyPattern matches this workspace's one convention for a server
+// program announcing where it's listening (see
+// tests/golang/greeter_service/server.go's "listening on %s"), mirroring
+// grpcservice's own harness tests.
+var readyPattern = regexp.MustCompile(`listening on (\S+)`)
+
+// RunIntegrationTest builds svc (the GoGrpcService named by it.Service)
+// and a test binary from it's own sources, then uses grpcservice.Harness
+// to run the server, wait for it to announce readiness, and run the
+// client test binary against it, reporting whether it passed.
+func RunIntegrationTest(ws *workspace.Workspace, label string, it *grpcservice.IntegrationTest, svc *grpcservice.Service) (output string, passed bool, err error) {
+	svcEntry, ok := ws.Entries[it.Service]
+	if !ok {
+		return "", false, fmt.Errorf("execute: no such target %q", it.Service)
+	}
+
+	serverBin, serverCleanup, err := BuildProgram(ws, it.Service)
+	if err != nil {
+		return "", false, err
+	}
+	defer serverCleanup()
+
+	clientBin, clientCleanup, err := buildTestBinary(ws, label, it.Srcs, it.Deps)
+	if err != nil {
+		return "", false, err
+	}
+	defer clientCleanup()
+
+	h := &grpcservice.Harness{
+		NewServerCmd: func() *exec.Cmd {
+			args := append([]string{it.AddrFlag + "=localhost:0"}, svc.ServerArgs()...)
+			cmd := exec.Command(serverBin, args...)
+			// svc.CertFile/KeyFile are written relative to the service's
+			// own BUILD directory, so the server (and the client, which
+			// points at the same cert to verify it) must run from there.
+			cmd.Dir = svcEntry.Dir
+			return cmd
+		},
+		ReadyPattern: readyPattern,
+		ReadyTimeout: it.ReadyTimeout,
+	}
+
+	out, runErr := h.Run(func(addr string) *exec.Cmd {
+		args := append([]string{it.AddrFlag + "=" + addr}, it.ClientArgsForService(svc)...)
+		for _, sc := range it.Streaming {
+			args = append(args, sc.ClientArgs()...)
+		}
+		cmd := exec.Command(clientBin, args...)
+		cmd.Dir = svcEntry.Dir
+		return cmd
+	})
+	if runErr == nil {
+		return out, true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		return out, false, nil
+	}
+	return out, false, fmt.Errorf("execute: running %s: %w", label, runErr)
+}
+
+// buildTestBinary materializes srcs/deps (relative to label's own BUILD
+// directory) into an overlay and compiles them with `go test -c`,
+// returning a standalone test binary rather than running it directly, so
+// callers that need to control when and with what flags it runs (like
+// RunIntegrationTest) can invoke it themselves.
+func buildTestBinary(ws *workspace.Workspace, label string, srcs, deps []string) (binPath string, cleanup func(), err error) {
+	e, ok := ws.Entries[label]
+	if !ok {
+		return "", nil, fmt.Errorf("execute: no such target %q", label)
+	}
+
+	o, err := newOverlay()
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(o.dir) }
+
+	if err := o.addDepClosure(ws, resolveLabels(label, deps)); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if err := o.addLocalPackage(rootImportPath, e.Dir, srcs, nil); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if err := o.writeGoMod(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	binDir := filepath.Join(o.dir, "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	binPath = filepath.Join(binDir, e.Target.Attr("name").AsString())
+	cmd := o.goCmd("test", "-c", "-o", binPath, rootImportPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("execute: building %s: %w\n%s", label, err, out)
+	}
+	return binPath, cleanup, nil
+}
+
+// resolveLabels resolves each of refs (as written in fromLabel's BUILD
+// file) to its absolute label.
+func resolveLabels(fromLabel string, refs []string) []string {
+	out := make([]string, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, workspace.Resolve(fromLabel, r))
+	}
+	return out
+}
+
+// overlay is a workspace materialized onto disk: a temp directory laid
+// out as a Go module per package (a "replace" target must be its own
+// module), ready to be driven with go build/run/test.
+type overlay struct {
+	dir      string
+	packages map[string]bool // import paths already materialized
+}
+
+func newOverlay() (*overlay, error) {
+	dir, err := os.MkdirTemp("", "ybt-overlay-")
+	if err != nil {
+		return nil, err
+	}
+	return &overlay{dir: dir, packages: map[string]bool{}}, nil
+}
+
+func (o *overlay) srcDir(importPath string) string {
+	return filepath.Join(o.dir, "src", importPath)
+}
+
+// addLocalPackage copies every file in srcs (relative to dir) plus
+// extraFiles' literal content into the overlay under importPath, and
+// gives the package its own minimal go.mod.
+func (o *overlay) addLocalPackage(importPath, dir string, srcs []string, extraFiles map[string]string) error {
+	if o.packages[importPath] {
+		return nil
+	}
+	o.packages[importPath] = true
+
+	pkgDir := o.srcDir(importPath)
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		return err
+	}
+	for _, src := range srcs {
+		content, err := os.ReadFile(filepath.Join(dir, src))
+		if err != nil {
+			return fmt.Errorf("execute: reading %s: %w", filepath.Join(dir, src), err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, filepath.Base(src)), content, 0o644); err != nil {
+			return err
+		}
+	}
+	for name, content := range extraFiles {
+		if err := os.WriteFile(filepath.Join(pkgDir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return o.writePackageGoMod(importPath)
+}
+
+// addGeneratedPackage materializes a proto_go_library's codegen output
+// (files keyed by name, as returned by protogolibrary.Library.Generate)
+// under its own import path.
+func (o *overlay) addGeneratedPackage(importPath string, files map[string][]byte) error {
+	if o.packages[importPath] {
+		return nil
+	}
+	o.packages[importPath] = true
+
+	pkgDir := o.srcDir(importPath)
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(pkgDir, filepath.Base(name)), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return o.writePackageGoMod(importPath)
+}
+
+func (o *overlay) writePackageGoMod(importPath string) error {
+	content := fmt.Sprintf("module %s\n\ngo 1.21\n", importPath)
+	return os.WriteFile(filepath.Join(o.srcDir(importPath), "go.mod"), []byte(content), 0o644)
+}
+
+// addDepClosure recursively materializes every go_library/proto_go_library
+// in labels, plus their own deps in turn. "@repo//:target" labels are
+// external modules (see externalModules) rather than workspace packages,
+// so they're skipped here.
+func (o *overlay) addDepClosure(ws *workspace.Workspace, labels []string) error {
+	for _, label := range labels {
+		if strings.HasPrefix(label, "@") {
+			continue
+		}
+		e, ok := ws.Entries[label]
+		if !ok {
+			return fmt.Errorf("execute: no such target %q", label)
+		}
+
+		switch e.Target.Rule {
+		case "go_library":
+			importPath := e.Target.Attr("importpath").AsString()
+			if o.packages[importPath] {
+				continue
+			}
+			srcs := e.Target.Attr("srcs").AsStringList()
+			deps := resolveLabels(label, e.Target.Attr("deps").AsStringList())
+			if err := o.addLocalPackage(importPath, e.Dir, srcs, nil); err != nil {
+				return err
+			}
+			if err := o.addDepClosure(ws, deps); err != nil {
+				return err
+			}
+
+		case "proto_go_library":
+			importPath := e.Target.Attr("go_import_path").AsString()
+			if o.packages[importPath] {
+				continue
+			}
+			deps := resolveLabels(label, e.Target.Attr("deps").AsStringList())
+			if err := o.addDepClosure(ws, deps); err != nil {
+				return err
+			}
+			lib := &protogolibrary.Library{
+				Srcs:         e.Target.Attr("srcs").AsStringList(),
+				GoImportPath: importPath,
+				Plugins:      e.Target.Attr("plugins").AsString(),
+			}
+			files, err := lib.Generate(importRootFor(e.Dir, importPath), e.Dir)
+			if err != nil {
+				return fmt.Errorf("execute: generating %s: %w", label, err)
+			}
+			if err := o.addGeneratedPackage(importPath, files); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("execute: %s: unsupported dependency rule %q", label, e.Target.Rule)
+		}
+	}
+	return nil
+}
+
+// importRootFor derives the directory a proto_go_library's .proto import
+// statements are resolved relative to, from its own package dir and
+// go_import_path: it strips as many trailing components off dir as
+// importPath has segments. When dir ends in importPath itself (e.g. dir
+// ".../tests/proto/base_protos" with importPath "proto/base_protos"),
+// the result is the root those imports are written against; otherwise
+// (e.g. tests/proto/app with "foo.com/ybtproto/app") it is simply that
+// many levels above dir.
+func importRootFor(dir, importPath string) string {
+	root := dir
+	for range strings.Split(importPath, "/") {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// setupShim returns the content of a small generated Go file, in the same
+// package as srcs, that blank-imports each of helperLabels (a go_test's
+// Setup and Teardown packages) so their init() functions run before the
+// test binary's tests do. ybt has no separate Go-level "teardown" hook, so
+// Teardown packages are imported the same way Setup ones are; it's up to
+// each package's own init() to register whatever ordering it needs.
+func setupShim(ws *workspace.Workspace, dir string, srcs, helperLabels []string) (string, error) {
+	pkgName, err := packageNameOf(dir, srcs)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkgName)
+	for _, label := range sortedCopy(helperLabels) {
+		e, ok := ws.Entries[label]
+		if !ok {
+			return "", fmt.Errorf("execute: no such target %q", label)
+		}
+		fmt.Fprintf(&b, "\t_ %q\n", e.Target.Attr("importpath").AsString())
+	}
+	b.WriteString(")\n")
+	return b.String(), nil
+}
+
+// packageNameOf returns the package clause of the first file in srcs.
+func packageNameOf(dir string, srcs []string) (string, error) {
+	if len(srcs) == 0 {
+		return "", fmt.Errorf("execute: %s has no srcs to read a package name from", dir)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, srcs[0]))
+	if err != nil {
+		return "", err
+	}
+	var name string
+	if _, err := fmt.Sscanf(firstPackageLine(string(content)), "package %s", &name); err != nil {
+		return "", fmt.Errorf("execute: %s: no package clause found", srcs[0])
+	}
+	return name, nil
+}
+
+func firstPackageLine(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			return line
+		}
+	}
+	return ""
+}
+
+func sortedCopy(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
+
+// writeGoMod writes the overlay's root go.mod, requiring and replacing
+// every package materialized so far with its on-disk copy, plus every
+// externalModule this workspace's fixtures may import, pinned to the same
+// version the root go.mod resolves, so resolving them needs no network
+// access.
+func (o *overlay) writeGoMod() error {
+	var importPaths []string
+	for p := range o.packages {
+		importPaths = append(importPaths, p)
+	}
+	sort.Strings(importPaths)
+
+	var b strings.Builder
+	b.WriteString("module ybt.overlay\n\ngo 1.21\n\nrequire (\n")
+	for _, p := range importPaths {
+		fmt.Fprintf(&b, "\t%s v0.0.0-00010101000000-000000000000\n", p)
+	}
+	for _, m := range externalModules {
+		fmt.Fprintf(&b, "\t%s %s\n", m.path, m.version)
+	}
+	b.WriteString(")\n\nreplace (\n")
+	for _, p := range importPaths {
+		fmt.Fprintf(&b, "\t%s => ./src/%s\n", p, p)
+	}
+	b.WriteString(")\n")
+	return os.WriteFile(filepath.Join(o.dir, "go.mod"), []byte(b.String()), 0o644)
+}
+
+// goCmd builds a go-tool invocation rooted at the overlay, forced to
+// resolve every module from the local cache rather than the network.
+func (o *overlay) goCmd(args ...string) *exec.Cmd {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = o.dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off")
+	return cmd
+}
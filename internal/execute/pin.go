@@ -0,0 +1,13 @@
+package execute
+
+// This file exists solely to keep github.com/common-nighthawk/go-figure
+// resolvable from this module's own go.sum. Nothing in ybt's own sources
+// imports it for real — it's only ever referenced by path+version in
+// externalModules, to pin the overlay's offline build of workspace fixtures
+// like //tests/golang/hello that do import it (under their own, separate
+// tests/go.mod). Without a real import here, `go mod tidy` drops it
+// entirely, and overlay builds that need it have nothing to resolve
+// against with GOPROXY=off.
+import (
+	_ "github.com/common-nighthawk/go-figure"
+)
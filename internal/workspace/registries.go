@@ -0,0 +1,193 @@
+package workspace
+
+import (
+	"strings"
+	"time"
+
+	"resonai/ybt/internal/builders/gogoldentest"
+	"resonai/ybt/internal/builders/gotest"
+	"resonai/ybt/internal/builders/grpcservice"
+	"resonai/ybt/internal/builders/protogolibrary"
+	"resonai/ybt/internal/buildfile"
+)
+
+// GoTests returns every go_test target as a gotest.Registry keyed by
+// label.
+func (w *Workspace) GoTests() gotest.Registry {
+	reg := gotest.Registry{}
+	for _, e := range w.ByRule("go_test") {
+		t := e.Target
+		reg[e.Label] = &gotest.Test{
+			Name:     t.Attr("name").AsString(),
+			Srcs:     t.Attr("srcs").AsStringList(),
+			Deps:     w.resolveAll(e.Label, t.Attr("deps").AsStringList()),
+			TestArgs: t.Attr("test_args").AsStringList(),
+			TestEnv:  stringMap(t.Attr("test_env")),
+			Setup:    w.resolveAll(e.Label, t.Attr("setup").AsStringList()),
+			Teardown: w.resolveAll(e.Label, t.Attr("teardown").AsStringList()),
+		}
+	}
+	return reg
+}
+
+// GoldenTests returns every go_golden_test target as a
+// gogoldentest.GoldenTest keyed by label.
+func (w *Workspace) GoldenTests() map[string]*gogoldentest.GoldenTest {
+	reg := map[string]*gogoldentest.GoldenTest{}
+	for _, e := range w.ByRule("go_golden_test") {
+		t := e.Target
+		var cases []gogoldentest.Case
+		names := sortedKeys(t.Attr("cases").AsDict())
+		for _, name := range names {
+			c := t.Attr("cases").AsDict()[name]
+			cases = append(cases, gogoldentest.Case{
+				Name: name,
+				Argv: c.AsDict()["argv"].AsStringList(),
+			})
+		}
+		reg[e.Label] = &gogoldentest.GoldenTest{
+			Name:        t.Attr("name").AsString(),
+			Program:     Resolve(e.Label, t.Attr("program").AsString()),
+			TestdataDir: e.Dir + "/" + t.Attr("testdata").AsString(),
+			Cases:       cases,
+		}
+	}
+	return reg
+}
+
+// ProtoLibraries returns every proto_go_library target as a
+// protogolibrary.Library keyed by label.
+func (w *Workspace) ProtoLibraries() map[string]*protogolibrary.Library {
+	reg := map[string]*protogolibrary.Library{}
+	for _, e := range w.ByRule("proto_go_library") {
+		t := e.Target
+		reg[e.Label] = &protogolibrary.Library{
+			Name:         t.Attr("name").AsString(),
+			Srcs:         t.Attr("srcs").AsStringList(),
+			GoImportPath: t.Attr("go_import_path").AsString(),
+			Deps:         w.resolveAll(e.Label, t.Attr("deps").AsStringList()),
+			Plugins:      t.Attr("plugins").AsString(),
+		}
+	}
+	return reg
+}
+
+// GrpcServices returns every go_grpc_service target as a
+// grpcservice.Service keyed by label.
+func (w *Workspace) GrpcServices() map[string]*grpcservice.Service {
+	reg := map[string]*grpcservice.Service{}
+	for _, e := range w.ByRule("go_grpc_service") {
+		t := e.Target
+		reg[e.Label] = &grpcservice.Service{
+			Name:         t.Attr("name").AsString(),
+			Srcs:         t.Attr("srcs").AsStringList(),
+			Deps:         w.resolveAll(e.Label, t.Attr("deps").AsStringList()),
+			CertFile:     t.Attr("cert_file").AsString(),
+			KeyFile:      t.Attr("key_file").AsString(),
+			Interceptors: t.Attr("interceptors").AsStringList(),
+		}
+	}
+	return reg
+}
+
+// GrpcIntegrationTests returns every grpc_integration_test target as a
+// grpcservice.IntegrationTest keyed by label.
+func (w *Workspace) GrpcIntegrationTests() map[string]*grpcservice.IntegrationTest {
+	reg := map[string]*grpcservice.IntegrationTest{}
+	for _, e := range w.ByRule("grpc_integration_test") {
+		t := e.Target
+		timeout, _ := time.ParseDuration(t.Attr("ready_timeout").AsString())
+		var cases []grpcservice.StreamingCase
+		for _, name := range sortedKeys(t.Attr("streaming").AsDict()) {
+			sc := t.Attr("streaming").AsDict()[name]
+			cases = append(cases, grpcservice.StreamingCase{
+				Name:    name,
+				Sends:   sc.AsDict()["sends"].AsStringList(),
+				Expects: sc.AsDict()["expects"].AsStringList(),
+			})
+		}
+		reg[e.Label] = &grpcservice.IntegrationTest{
+			Name:         t.Attr("name").AsString(),
+			Srcs:         t.Attr("srcs").AsStringList(),
+			Deps:         w.resolveAll(e.Label, t.Attr("deps").AsStringList()),
+			Service:      Resolve(e.Label, t.Attr("service").AsString()),
+			AddrFlag:     t.Attr("addr_flag").AsString(),
+			ReadyTimeout: timeout,
+			Streaming:    cases,
+		}
+	}
+	return reg
+}
+
+// AutoImportsIndex derives the workspace half of an autoimports.Index from
+// every go_library, proto_go_library, and go_grpc_service target's
+// declared import path: each is registered under the alias a Go source
+// file would naturally use for it (its own last path segment, e.g. "pb"
+// is not guessable this way, but "hello_lib" and "base_protos" are) and
+// under the camel-cased form of its target name (e.g. "helloLib"), which
+// covers the alias convention this workspace's fixtures actually use.
+func (w *Workspace) AutoImportsIndex() map[string]string {
+	idx := map[string]string{}
+	for _, e := range w.ByRule("go_library", "proto_go_library", "go_grpc_service") {
+		t := e.Target
+		importPath := t.Attr("importpath").AsString()
+		if importPath == "" {
+			importPath = t.Attr("go_import_path").AsString()
+		}
+		if importPath == "" {
+			continue
+		}
+		name := t.Attr("name").AsString()
+		if i := strings.LastIndex(importPath, "/"); i >= 0 {
+			idx[importPath[i+1:]] = importPath
+		} else {
+			idx[importPath] = importPath
+		}
+		idx[camelCase(name)] = importPath
+	}
+	return idx
+}
+
+func camelCase(snake string) string {
+	parts := strings.Split(snake, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func (w *Workspace) resolveAll(fromLabel string, refs []string) []string {
+	out := make([]string, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, Resolve(fromLabel, r))
+	}
+	return out
+}
+
+func stringMap(v buildfile.Value) map[string]string {
+	dict := v.AsDict()
+	if dict == nil {
+		return nil
+	}
+	out := make(map[string]string, len(dict))
+	for k, e := range dict {
+		out[k] = e.AsString()
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j-1] > keys[j]; j-- {
+			keys[j-1], keys[j] = keys[j], keys[j-1]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,113 @@
+package workspace
+
+import "testing"
+
+func load(t *testing.T) *Workspace {
+	t.Helper()
+	w, err := Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestGoTestsMatchesHandMaintainedShape(t *testing.T) {
+	w := load(t)
+	reg := w.GoTests()
+
+	tt, ok := reg["//tests/golang/hello_lib:hello_lib_test"]
+	if !ok {
+		t.Fatal("missing hello_lib_test")
+	}
+	if len(tt.TestArgs) != 1 || tt.TestArgs[0] != "-greet_to=boomer" {
+		t.Fatalf("TestArgs = %v", tt.TestArgs)
+	}
+	if len(tt.Deps) != 1 || tt.Deps[0] != "//tests/golang/hello_lib:hello_lib" {
+		t.Fatalf("Deps = %v, want resolved hello_lib dep", tt.Deps)
+	}
+
+	goodbye, ok := reg["//tests/golang/goodbye:goodbye_test"]
+	if !ok {
+		t.Fatal("missing goodbye_test")
+	}
+	if len(goodbye.Setup) != 1 || goodbye.Setup[0] != "//tests/golang/goodbye_lib:test_utils" {
+		t.Fatalf("Setup = %v", goodbye.Setup)
+	}
+}
+
+func TestGoldenTestsResolveProgramLabelAndCases(t *testing.T) {
+	w := load(t)
+	reg := w.GoldenTests()
+
+	g, ok := reg["//tests/golang/hello:hello_proto_golden_test"]
+	if !ok {
+		t.Fatal("missing hello_proto_golden_test")
+	}
+	if g.Program != "//tests/golang/hello:hello_proto" {
+		t.Fatalf("Program = %q", g.Program)
+	}
+	if len(g.Cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(g.Cases))
+	}
+}
+
+func TestProtoLibrariesResolveGrpcPlugin(t *testing.T) {
+	w := load(t)
+	reg := w.ProtoLibraries()
+
+	greeter, ok := reg["//tests/proto/greeter:greeter_proto"]
+	if !ok {
+		t.Fatal("missing greeter_proto")
+	}
+	if greeter.Plugins != "grpc" {
+		t.Fatalf("Plugins = %q, want grpc", greeter.Plugins)
+	}
+	if len(greeter.Deps) != 1 || greeter.Deps[0] != "//tests/proto/base_protos:base_protos" {
+		t.Fatalf("Deps = %v", greeter.Deps)
+	}
+}
+
+func TestGrpcServicesAndIntegrationTests(t *testing.T) {
+	w := load(t)
+	services := w.GrpcServices()
+	svc, ok := services["//tests/golang/greeter_service:greeter_service"]
+	if !ok {
+		t.Fatal("missing greeter_service")
+	}
+	if svc.CertFile == "" {
+		t.Fatal("expected cert_file to be set")
+	}
+
+	its := w.GrpcIntegrationTests()
+	it, ok := its["//tests/golang/greeter_service:greeter_integration_test"]
+	if !ok {
+		t.Fatal("missing greeter_integration_test")
+	}
+	if it.Service != "//tests/golang/greeter_service:greeter_service" {
+		t.Fatalf("Service = %q", it.Service)
+	}
+	if len(it.Srcs) != 1 || it.Srcs[0] != "client_test.go" {
+		t.Fatalf("Srcs = %v", it.Srcs)
+	}
+	if len(it.Streaming) != 1 || it.Streaming[0].Name != "EchoStream" {
+		t.Fatalf("Streaming = %+v", it.Streaming)
+	}
+	if len(it.Streaming[0].Sends) != 2 {
+		t.Fatalf("Sends = %v", it.Streaming[0].Sends)
+	}
+}
+
+func TestAutoImportsIndexCoversFixtureAliases(t *testing.T) {
+	w := load(t)
+	idx := w.AutoImportsIndex()
+
+	if idx["hello_lib"] != "bar.com/hello_lib" {
+		t.Fatalf("hello_lib alias = %q", idx["hello_lib"])
+	}
+	if idx["helloLib"] != "bar.com/hello_lib" {
+		t.Fatalf("helloLib alias = %q", idx["helloLib"])
+	}
+	if idx["base_protos"] != "proto/base_protos" {
+		t.Fatalf("base_protos alias = %q", idx["base_protos"])
+	}
+}
@@ -0,0 +1,122 @@
+// Package workspace turns a tree of BUILD files into the label-indexed
+// target graph the rest of ybt builds on: it's the BUILD evaluator the
+// other builder packages were designed to sit behind, replacing the
+// hand-maintained registries that stood in for it.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"resonai/ybt/internal/buildfile"
+)
+
+// Entry is one target as loaded from a BUILD file, together with the
+// label it's addressable by and the directory its srcs are relative to.
+type Entry struct {
+	Label  string
+	Dir    string // absolute directory containing the BUILD file
+	Target buildfile.Target
+}
+
+// Workspace is the full set of targets loaded from every BUILD file under
+// a root directory, indexed by label.
+type Workspace struct {
+	Root    string
+	Entries map[string]Entry // label -> Entry
+}
+
+// Load walks root for files named BUILD, parses each, and indexes every
+// target it declares under its "//pkg:name" label, where pkg is the
+// target's directory relative to root.
+func Load(root string) (*Workspace, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{Root: absRoot, Entries: map[string]Entry{}}
+
+	err = filepath.Walk(absRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "BUILD" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		targets, err := buildfile.Parse(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dir := filepath.Dir(path)
+		rel, err := filepath.Rel(absRoot, dir)
+		if err != nil {
+			return err
+		}
+		pkg := "//" + filepath.ToSlash(rel)
+		if rel == "." {
+			pkg = "/"
+		}
+		for _, t := range targets {
+			name := t.Attr("name").AsString()
+			if name == "" {
+				return fmt.Errorf("%s: %s target missing name", path, t.Rule)
+			}
+			label := pkg + ":" + name
+			w.Entries[label] = Entry{Label: label, Dir: dir, Target: t}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Resolve turns a label reference written inside fromPkg's BUILD file
+// (either a bare ":name", a "//pkg" shorthand for "//pkg:basename(pkg)",
+// or an already-absolute "//pkg:name") into the absolute label it refers
+// to, matching ybt's BUILD-file label syntax.
+func Resolve(fromLabel, ref string) string {
+	pkg := fromLabel[:strings.LastIndex(fromLabel, ":")]
+	if strings.HasPrefix(ref, ":") {
+		return pkg + ref
+	}
+	if strings.Contains(ref, ":") {
+		return ref
+	}
+	// "//pkg" with no ":name" means the target named after pkg's last
+	// path segment.
+	return ref + ":" + ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// Get looks up a target by label, which may be written relative to
+// fromLabel's package (see Resolve).
+func (w *Workspace) Get(fromLabel, ref string) (Entry, error) {
+	label := Resolve(fromLabel, ref)
+	e, ok := w.Entries[label]
+	if !ok {
+		return Entry{}, fmt.Errorf("workspace: no such target %q", label)
+	}
+	return e, nil
+}
+
+// ByRule returns every entry whose target's Rule matches any of rules.
+func (w *Workspace) ByRule(rules ...string) []Entry {
+	want := map[string]bool{}
+	for _, r := range rules {
+		want[r] = true
+	}
+	var out []Entry
+	for _, e := range w.Entries {
+		if want[e.Target.Rule] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package workspace
+
+import "testing"
+
+func TestLoadIndexesFixtureTargetsByLabel(t *testing.T) {
+	w, err := Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := w.Entries["//tests/golang/hello_lib:hello_lib_test"]
+	if !ok {
+		t.Fatalf("missing //tests/golang/hello_lib:hello_lib_test; have %d entries", len(w.Entries))
+	}
+	if e.Target.Rule != "go_test" {
+		t.Fatalf("Rule = %q, want go_test", e.Target.Rule)
+	}
+	if got := e.Target.Attr("test_args").AsStringList(); len(got) != 1 || got[0] != "-greet_to=boomer" {
+		t.Fatalf("test_args = %v", got)
+	}
+}
+
+func TestResolveLabelReferences(t *testing.T) {
+	cases := []struct {
+		from, ref, want string
+	}{
+		{"//tests/golang/hello_lib:hello_lib_test", ":hello_lib", "//tests/golang/hello_lib:hello_lib"},
+		{"//tests/golang/hello:hello_proto_golden_test", "//tests/proto/base_protos", "//tests/proto/base_protos:base_protos"},
+		{"//tests/golang/hello:hello", "//tests/golang/hello_lib:hello_lib", "//tests/golang/hello_lib:hello_lib"},
+	}
+	for _, c := range cases {
+		if got := Resolve(c.from, c.ref); got != c.want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", c.from, c.ref, got, c.want)
+		}
+	}
+}
+
+func TestByRuleFindsGoTests(t *testing.T) {
+	w, err := Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := w.ByRule("go_test")
+	if len(tests) < 2 {
+		t.Fatalf("got %d go_test entries, want at least 2", len(tests))
+	}
+}
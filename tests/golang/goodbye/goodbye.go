@@ -0,0 +1,17 @@
+package main
+
+import "fmt"
+
+var name = "World"
+
+func initName(n string) {
+	name = n
+}
+
+func getName() string {
+	return name
+}
+
+func main() {
+	fmt.Printf("goodbye %s\n", getName())
+}
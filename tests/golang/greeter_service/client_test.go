@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	base "proto/base_protos"
+	pb "proto/greeter"
+)
+
+var (
+	addr          = flag.String("addr", "", "address of the greeter server under test")
+	tlsCertFile   = flag.String("tls_cert_file", "", "CA/server cert the client trusts; plaintext when empty")
+	tlsServerName = flag.String("tls_server_name", "localhost", "expected server name in the TLS cert")
+	echoSends     = flag.String("echo_stream_sends", "", "comma-separated messages TestEchoStream sends on EchoStream")
+	echoExpects   = flag.String("echo_stream_expects", "", "comma-separated messages TestEchoStream expects back, in order")
+)
+
+func dial(t *testing.T) *grpc.ClientConn {
+	creds := insecure.NewCredentials()
+	if *tlsCertFile != "" {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(*tlsCertFile, *tlsServerName)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestSayHello(t *testing.T) {
+	assert := assert.New(t)
+
+	conn := dial(t)
+	defer conn.Close()
+
+	client := pb.NewGreeterClient(conn)
+	who := "boomer"
+	reply, err := client.SayHello(context.Background(), &base.Hello{Message: &who})
+	assert.NoError(err)
+	assert.Equal("hello boomer", reply.GetMessage())
+}
+
+// TestEchoStream exercises the EchoStream streaming case declared on the
+// greeter_integration_test target: every message in echoSends is expected
+// back, in order, against echoExpects. Sends/expects arrive as flags
+// (see StreamingCase.ClientArgs) rather than being hardcoded here, so this
+// test actually exercises whatever cases greeter_integration_test's BUILD
+// entry declares.
+func TestEchoStream(t *testing.T) {
+	assert := assert.New(t)
+
+	conn := dial(t)
+	defer conn.Close()
+
+	client := pb.NewGreeterClient(conn)
+	stream, err := client.EchoStream(context.Background())
+	assert.NoError(err)
+
+	sends := strings.Split(*echoSends, ",")
+	expects := strings.Split(*echoExpects, ",")
+	for i, msg := range sends {
+		assert.NoError(stream.Send(&base.Hello{Message: &msg}))
+		reply, err := stream.Recv()
+		assert.NoError(err)
+		assert.Equal(expects[i], reply.GetMessage())
+	}
+	assert.NoError(stream.CloseSend())
+}
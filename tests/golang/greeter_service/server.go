@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	base "proto/base_protos"
+	pb "proto/greeter"
+)
+
+type server struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (s *server) SayHello(ctx context.Context, in *base.Hello) (*base.Hello, error) {
+	msg := fmt.Sprintf("hello %s", in.GetMessage())
+	return &base.Hello{Message: &msg}, nil
+}
+
+func (s *server) EchoStream(stream pb.Greeter_EchoStreamServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(in); err != nil {
+			return err
+		}
+	}
+}
+
+// loggingInterceptor is the one interceptor this fixture knows how to
+// register by name, selected via repeated -interceptor flags.
+func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	fmt.Printf("call %s\n", info.FullMethod)
+	return handler(ctx, req)
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:0", "address to listen on")
+	certFile := flag.String("tls_cert_file", "", "TLS certificate file; plaintext when empty")
+	keyFile := flag.String("tls_key_file", "", "TLS key file; plaintext when empty")
+	var interceptors stringSliceFlag
+	flag.Var(&interceptors, "interceptor", "name of an interceptor to register; may be repeated")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		panic(err)
+	}
+
+	var opts []grpc.ServerOption
+	if *certFile != "" || *keyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	for _, name := range interceptors {
+		switch name {
+		case "logging":
+			opts = append(opts, grpc.ChainUnaryInterceptor(loggingInterceptor))
+		default:
+			panic(fmt.Sprintf("unknown interceptor %q", name))
+		}
+	}
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterGreeterServer(s, &server{})
+
+	fmt.Printf("listening on %s\n", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		panic(err)
+	}
+}
+
+type stringSliceFlag []string
+
+func (f *stringSliceFlag) String() string {
+	return fmt.Sprint([]string(*f))
+}
+
+func (f *stringSliceFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
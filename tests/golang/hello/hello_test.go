@@ -1,7 +1,7 @@
 package main
 
 import "testing"
-import hello "foo.com/hello"
+import hello "bar.com/hello_lib"
 
 func TestGetGreet(t *testing.T) {
 	if greet := hello.GetGreet("boomer"); greet != "hello boomer" {
@@ -5,8 +5,6 @@ import (
   "testing"
 
 	"github.com/stretchr/testify/assert"
-
-  hello "bar.com/hello_lib"
 )
 
 var (
@@ -14,16 +12,16 @@ var (
 )
 
 func TestGetGreet(t *testing.T) {
-	greet := hello.GetGreet("boomer")
+	greet := GetGreet("boomer")
 	assert.Equalf(t, "hello boomer", greet, "Unexpected greeting \"%s\"", greet)
 }
 
 func TestPrintFooFromEnv(t *testing.T) {
-	greet := hello.PrintFooFromEnv()
+	greet := PrintFooFromEnv()
 	assert.Equalf(t, "hello foo", greet, "Unexpected greeting \"%s\"", greet)
 }
 
 func TestGetGreetFromFlag(t *testing.T) {
-	greet := hello.GetGreet(*greetTo)
+	greet := GetGreet(*greetTo)
 	assert.Equalf(t, "hello boomer", greet, "Unexpected greeting \"%s\"", greet)
 }